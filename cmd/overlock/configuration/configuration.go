@@ -1,9 +1,12 @@
 package configuration
 
+import "github.com/web-seven/overlock/cmd/overlock/packages"
+
 type Cmd struct {
-	Apply  applyCmd  `cmd:"" help:"Apply Crossplane Configuration."`
-	List   listCmd   `cmd:"" help:"Apply Crossplane Configuration."`
-	Load   loadCmd   `cmd:"" help:"Load Crossplane Configuration from archive."`
-	Serve  serveCmd  `cmd:"" help:"Serve Crossplane Configuration from filesystem."`
-	Delete deleteCmd `cmd:"" help:"Delete Crossplane Configuration."`
+	Apply  packages.ApplyCmd `cmd:"" set:"kind=configuration" help:"Apply Crossplane Configuration."`
+	List   listCmd           `cmd:"" help:"Apply Crossplane Configuration."`
+	Load   packages.LoadCmd  `cmd:"" set:"kind=configuration" help:"Load Crossplane Configuration from archive."`
+	Serve  serveCmd          `cmd:"" help:"Serve Crossplane Configuration from filesystem."`
+	Delete deleteCmd         `cmd:"" help:"Delete Crossplane Configuration."`
+	Deps   depsCmd           `cmd:"" help:"Show dependency graph of Crossplane Configuration."`
 }
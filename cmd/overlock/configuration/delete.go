@@ -3,11 +3,12 @@ package configuration
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"go.uber.org/zap"
 	"k8s.io/client-go/dynamic"
 
-	"github.com/web-seven/overlock/pkg/configuration"
+	"github.com/web-seven/overlock/internal/xpkg"
 )
 
 type deleteCmd struct {
@@ -15,8 +16,14 @@ type deleteCmd struct {
 }
 
 func (c *deleteCmd) Run(ctx context.Context, dynamic *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	if err := configuration.DeleteConfiguration(ctx, c.ConfigurationURL, dynamic, logger); err != nil {
-		return fmt.Errorf("failed to delete configuration: %w", err)
+	pkg, err := xpkg.New("configuration")
+	if err != nil {
+		return err
+	}
+	for _, ref := range strings.Split(c.ConfigurationURL, ",") {
+		if err := pkg.Delete(ctx, dynamic, ref, logger); err != nil {
+			return fmt.Errorf("failed to delete configuration: %w", err)
+		}
 	}
 	return nil
 }
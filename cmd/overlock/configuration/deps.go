@@ -0,0 +1,131 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/packages"
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type depsCmd struct {
+	Package   string `arg:"" help:"Configuration reference, or name of installed Configuration with --installed."`
+	Installed bool   `help:"Read dependencies of active revision of installed Configuration from Crossplane lock."`
+	Output    string `short:"o" enum:"tree,dot,json" default:"tree" help:"Output format: tree, dot or json."`
+}
+
+func (c *depsCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	var graph *packages.GraphNode
+	if c.Installed {
+		var err error
+		graph, err = installedGraph(ctx, dc, c.Package, logger)
+		if err != nil {
+			return err
+		}
+	} else {
+		graph = packages.ResolveGraph(c.Package, installedVersions(ctx, dc, logger))
+	}
+	switch c.Output {
+	case "dot":
+		fmt.Print(graph.DOT())
+	case "json":
+		content, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(content))
+	default:
+		root := pterm.TreeNode{Children: []pterm.TreeNode{treeNode(graph)}}
+		return pterm.DefaultTree.WithRoot(root).Render()
+	}
+	return nil
+}
+
+// Graph of installed Configuration from Crossplane lock, which records
+// dependencies of its active revision
+func installedGraph(ctx context.Context, dc dynamic.Interface, ref string, logger *zap.SugaredLogger) (*packages.GraphNode, error) {
+	pkg, err := xpkg.New("configuration")
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := pkg.Revisions(ctx, dc, ref)
+	if err != nil {
+		return nil, err
+	}
+	active := ""
+	for _, rev := range revisions {
+		if rev.Active {
+			active = rev.Name
+		}
+	}
+	if active == "" {
+		return nil, fmt.Errorf("no active revision of Configuration %s found", ref)
+	}
+	logger.Debugf("Active revision: %s", active)
+	lock, err := packages.ReadLock(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	return packages.LockGraph(active, lock)
+}
+
+// Installed versions of packages of all kinds by repository
+func installedVersions(ctx context.Context, dc dynamic.Interface, logger *zap.SugaredLogger) map[string]string {
+	versions := map[string]string{}
+	for _, kind := range xpkg.Kinds() {
+		pkg, err := xpkg.New(kind)
+		if err != nil {
+			continue
+		}
+		installed, err := pkg.List(ctx, dc)
+		if err != nil {
+			logger.Debugf("Cannot list %s packages: %v", kind, err)
+			continue
+		}
+		for _, p := range installed {
+			repo, version := packages.SplitReference(p.Package)
+			versions[repo] = version
+		}
+	}
+	return versions
+}
+
+func treeNode(node *packages.GraphNode) pterm.TreeNode {
+	text := []string{node.Repository}
+	if node.Constraint != "" {
+		text = append(text, node.Constraint)
+	}
+	if node.Version != "" && node.Version != node.Constraint {
+		text = append(text, "-> "+node.Version)
+	}
+	if node.Installed != "" {
+		text = append(text, "(installed "+node.Installed+")")
+	}
+	text = append(text, stateStyle(node.State).Sprint("["+node.State+"]"))
+	if node.Reason != "" {
+		text = append(text, node.Reason)
+	}
+
+	tree := pterm.TreeNode{Text: strings.Join(text, " ")}
+	for _, dep := range node.Dependencies {
+		tree.Children = append(tree.Children, treeNode(dep))
+	}
+	return tree
+}
+
+func stateStyle(state string) *pterm.Style {
+	switch state {
+	case packages.StateSatisfied:
+		return pterm.NewStyle(pterm.FgGreen)
+	case packages.StateMissing:
+		return pterm.NewStyle(pterm.FgYellow)
+	default:
+		return pterm.NewStyle(pterm.FgRed)
+	}
+}
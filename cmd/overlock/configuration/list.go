@@ -8,17 +8,24 @@ import (
 	"go.uber.org/zap"
 	"k8s.io/client-go/dynamic"
 
-	"github.com/web-seven/overlock/pkg/configuration"
+	"github.com/web-seven/overlock/internal/xpkg"
 )
 
 type listCmd struct {
 }
 
 func (listCmd) Run(ctx context.Context, dynamicClient *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	configurations := configuration.GetConfigurations(ctx, dynamicClient)
+	pkg, err := xpkg.New("configuration")
+	if err != nil {
+		return err
+	}
+	configurations, err := pkg.List(ctx, dynamicClient)
+	if err != nil {
+		return fmt.Errorf("failed to list configurations: %w", err)
+	}
 	table := pterm.TableData{[]string{"NAME", "PACKAGE"}}
 	for _, conf := range configurations {
-		table = append(table, []string{conf.Name, conf.Spec.Package})
+		table = append(table, []string{conf.Name, conf.Package})
 	}
 	if err := pterm.DefaultTable.WithHasHeader().WithData(table).Render(); err != nil {
 		return fmt.Errorf("failed to render table: %w", err)
@@ -7,7 +7,7 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 
-	"github.com/web-seven/overlock/pkg/configuration"
+	"github.com/web-seven/overlock/internal/xpkg"
 )
 
 type serveCmd struct {
@@ -15,5 +15,9 @@ type serveCmd struct {
 }
 
 func (c *serveCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, config *rest.Config, logger *zap.SugaredLogger) error {
-	return configuration.Serve(ctx, dc, config, logger, c.Path)
+	pkg, err := xpkg.New("configuration")
+	if err != nil {
+		return err
+	}
+	return pkg.Serve(ctx, config, dc, xpkg.ServeOptions{Path: c.Path}, logger)
 }
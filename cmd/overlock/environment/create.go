@@ -32,6 +32,10 @@ type createOptions struct {
 	Functions                 []string `optional:"" help:"List of functions to apply to the environment."`
 	CreateAdminServiceAccount bool     `optional:"" help:"Create admin service account with cluster-admin privileges."`
 	AdminServiceAccountName   string   `optional:"" help:"Name for the admin service account. Only relevant when create-admin-service-account is enabled. Defaults to 'overlock-admin' if not specified."`
+	VerifyKey                 string   `optional:"" help:"Path to public key, only packages signed with it could be installed."`
+	VerifyImages              []string `optional:"" help:"Package image patterns verified with verify key. Defaults to packages of local registry."`
+	Locked                    bool     `optional:"" help:"Install packages with dependencies pinned to digests of lock file."`
+	LockFile                  string   `optional:"" help:"Path to lock file." default:"overlock.lock"`
 }
 
 func (c *createCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
@@ -74,6 +78,8 @@ func (c *createCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
 		WithConfigurations(c.Configurations).
 		WithFunctions(c.Functions).
 		WithAdminServiceAccount(c.CreateAdminServiceAccount, c.AdminServiceAccountName).
+		WithPackageVerification(c.VerifyKey, c.VerifyImages).
+		WithLockedPackages(c.Locked, c.LockFile).
 		Create(ctx, logger)
 }
 
@@ -0,0 +1,27 @@
+package environment
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/web-seven/overlock/pkg/environment"
+)
+
+type uninstallCmd struct {
+	Context              string `required:"" short:"c" help:"Kubernetes context where Environment engine will be uninstalled."`
+	OrphanResources      bool   `optional:"" help:"Keep external resources of composite resources by orphaning their managed resources."`
+	KeepPolicyController bool   `optional:"" help:"Keep Kyverno policy controller installed."`
+	KeepCertManager      bool   `optional:"" help:"Keep cert-manager installed."`
+	Confirm              bool   `optional:"" short:"y" help:"Confirm uninstall of overlock engine." default:"false"`
+}
+
+func (c *uninstallCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	return environment.
+		New("", c.Context).
+		WithContext(c.Context).
+		WithOrphanResources(c.OrphanResources).
+		WithKeepPolicyController(c.KeepPolicyController).
+		WithKeepCertManager(c.KeepCertManager).
+		Uninstall(ctx, c.Confirm, logger)
+}
@@ -9,11 +9,13 @@ import (
 )
 
 type upgradeCmd struct {
-	Name                      string `arg:"" required:"" help:"Environment name where engine will be upgraded."`
-	Engine                    string `optional:"" help:"Specifies the Kubernetes engine to use for the runtime environment." default:"kind"`
-	Context                   string `optional:"" short:"c" help:"Kubernetes context where Environment will be upgraded."`
-	CreateAdminServiceAccount bool   `optional:"" help:"Create admin service account with cluster-admin privileges."`
-	AdminServiceAccountName   string `optional:"" help:"Name for the admin service account. Only relevant when create-admin-service-account is enabled. Defaults to 'overlock-admin' if not specified."`
+	Name                      string   `arg:"" required:"" help:"Environment name where engine will be upgraded."`
+	Engine                    string   `optional:"" help:"Specifies the Kubernetes engine to use for the runtime environment." default:"kind"`
+	Context                   string   `optional:"" short:"c" help:"Kubernetes context where Environment will be upgraded."`
+	CreateAdminServiceAccount bool     `optional:"" help:"Create admin service account with cluster-admin privileges."`
+	AdminServiceAccountName   string   `optional:"" help:"Name for the admin service account. Only relevant when create-admin-service-account is enabled. Defaults to 'overlock-admin' if not specified."`
+	VerifyKey                 string   `optional:"" help:"Path to public key, only packages signed with it could be installed."`
+	VerifyImages              []string `optional:"" help:"Package image patterns verified with verify key. Defaults to packages of local registry."`
 }
 
 func (c *upgradeCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
@@ -21,5 +23,6 @@ func (c *upgradeCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
 		New(c.Engine, c.Name).
 		WithContext(c.Context).
 		WithAdminServiceAccount(c.CreateAdminServiceAccount, c.AdminServiceAccountName).
+		WithPackageVerification(c.VerifyKey, c.VerifyImages).
 		Upgrade(ctx, logger)
 }
@@ -2,8 +2,9 @@ package function
 
 import (
 	"context"
+	"strings"
 
-	"github.com/web-seven/overlock/internal/function"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"go.uber.org/zap"
 	"k8s.io/client-go/dynamic"
 )
@@ -13,5 +14,14 @@ type deleteCmd struct {
 }
 
 func (c *deleteCmd) Run(ctx context.Context, dynamic *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	return function.DeleteFunction(ctx, c.FunctionURL, dynamic, logger)
+	pkg, err := xpkg.New("function")
+	if err != nil {
+		return err
+	}
+	for _, ref := range strings.Split(c.FunctionURL, ",") {
+		if err := pkg.Delete(ctx, dynamic, ref, logger); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -1,9 +1,15 @@
 package function
 
+import (
+	"github.com/web-seven/overlock/cmd/overlock/packages"
+	"github.com/web-seven/overlock/cmd/overlock/runtimeconfig"
+)
+
 type Cmd struct {
-	Apply  applyCmd  `cmd:"" help:"Apply Crossplane Function."`
-	List   listCmd   `cmd:"" help:"Apply Crossplane Function."`
-	Load   loadCmd   `cmd:"" help:"Load Crossplane Function from archive."`
-	Serve  serveCmd  `cmd:"" help:"Watch changes of Function, build and load."`
-	Delete deleteCmd `cmd:"" help:"Delete Crossplane Function."`
+	Apply         packages.ApplyCmd `cmd:"" set:"kind=function" help:"Apply Crossplane Function."`
+	List          listCmd           `cmd:"" help:"Apply Crossplane Function."`
+	Load          packages.LoadCmd  `cmd:"" set:"kind=function" help:"Load Crossplane Function from archive."`
+	Serve         serveCmd          `cmd:"" help:"Watch changes of Function, build and load."`
+	Delete        deleteCmd         `cmd:"" help:"Delete Crossplane Function."`
+	RuntimeConfig runtimeconfig.Cmd `cmd:"" name:"runtime-config" set:"kind=function" help:"Manage DeploymentRuntimeConfig of Function."`
 }
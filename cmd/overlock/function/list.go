@@ -4,7 +4,7 @@ import (
 	"context"
 
 	"github.com/pterm/pterm"
-	"github.com/web-seven/overlock/internal/function"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"go.uber.org/zap"
 
 	"k8s.io/client-go/dynamic"
@@ -14,10 +14,17 @@ type listCmd struct {
 }
 
 func (listCmd) Run(ctx context.Context, dynamicClient *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	functions := function.GetFunctions(ctx, dynamicClient)
+	pkg, err := xpkg.New("function")
+	if err != nil {
+		return err
+	}
+	functions, err := pkg.List(ctx, dynamicClient)
+	if err != nil {
+		return err
+	}
 	table := pterm.TableData{[]string{"NAME", "PACKAGE"}}
 	for _, conf := range functions {
-		table = append(table, []string{conf.Name, conf.Spec.Package})
+		table = append(table, []string{conf.Name, conf.Package})
 	}
 	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
 	return nil
@@ -5,15 +5,22 @@ import (
 
 	"go.uber.org/zap"
 
-	"github.com/web-seven/overlock/internal/function"
+	"github.com/web-seven/overlock/cmd/overlock/packages"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
 
 type serveCmd struct {
 	Path string `default:"./" arg:"" help:"Path to package directory"`
+
+	BuildFlags packages.BuildFlags `embed:""`
 }
 
 func (c *serveCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, config *rest.Config, logger *zap.SugaredLogger) error {
-	return function.Serve(ctx, dc, config, logger, c.Path)
+	pkg, err := xpkg.New("function")
+	if err != nil {
+		return err
+	}
+	return pkg.Serve(ctx, config, dc, xpkg.ServeOptions{Path: c.Path, Build: c.BuildFlags.Config()}, logger)
 }
@@ -0,0 +1,5 @@
+package lock
+
+type Cmd struct {
+	Update updateCmd `cmd:"" help:"Resolve packages to digests and write them to lock file."`
+}
@@ -0,0 +1,48 @@
+package lock
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/web-seven/overlock/internal/lock"
+	"github.com/web-seven/overlock/pkg/registry"
+)
+
+type updateCmd struct {
+	Refs []string `arg:"" optional:"" help:"Package references to add or update, all locked packages if empty."`
+	File string   `optional:"" help:"Path to lock file." default:"overlock.lock"`
+}
+
+func (c *updateCmd) Run(ctx context.Context, client *kubernetes.Clientset, logger *zap.SugaredLogger) error {
+	l, err := lock.Load(c.File)
+	if err != nil {
+		return err
+	}
+	keychain, err := registry.Keychain(ctx, client)
+	if err != nil {
+		return err
+	}
+	auth := crane.WithAuthFromKeychain(keychain)
+	if len(c.Refs) == 0 {
+		if err := l.Update(ctx, logger, auth); err != nil {
+			return err
+		}
+	}
+	for _, ref := range c.Refs {
+		logger.Debugf("Resolving %s", ref)
+		pkg, err := lock.Resolve(ctx, ref, auth)
+		if err != nil {
+			return err
+		}
+		l.Set(*pkg)
+		logger.Infof("%s %s locked to %s", pkg.Kind, ref, pkg.Digest)
+	}
+	if err := l.Save(c.File); err != nil {
+		return err
+	}
+	logger.Infof("Lock file %s updated.", c.File)
+	return nil
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/web-seven/overlock/cmd/overlock/configuration"
 	"github.com/web-seven/overlock/cmd/overlock/environment"
 	"github.com/web-seven/overlock/cmd/overlock/function"
+	"github.com/web-seven/overlock/cmd/overlock/lock"
+	"github.com/web-seven/overlock/cmd/overlock/packages"
 	"github.com/web-seven/overlock/cmd/overlock/provider"
 	"github.com/web-seven/overlock/cmd/overlock/version"
 	"github.com/web-seven/overlock/internal/engine"
@@ -172,7 +174,9 @@ type cli struct {
 	InstallCompletions kongplete.InstallCompletions `cmd:"" help:"Install shell completions"`
 	Provider           provider.Cmd                 `cmd:"" name:"provider" aliases:"prv" help:"Overlock Provider commands"`
 	Function           function.Cmd                 `cmd:"" name:"function" aliases:"fnc" help:"Overlock Function commands"`
-	// Search             registry.SearchCmd           `cmd:"" help:"Search for packages"`
+	Package            packages.Cmd                 `cmd:"" name:"package" aliases:"pkg" help:"Overlock Package commands for all package kinds"`
+	Search             registry.SearchCmd           `cmd:"" help:"Search for packages"`
+	Lock               lock.Cmd                     `cmd:"" help:"Manage lock file of packages pinned to digests"`
 	// Generate           generate.Cmd                 `cmd:"" help:"Generate example by XRD YAML file"`
 }
 
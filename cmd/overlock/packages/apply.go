@@ -0,0 +1,30 @@
+package packages
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+// Apply command of package kind, set with kind variable of parent command,
+// like set:"kind=function"
+type ApplyCmd struct {
+	Link    string        `arg:"" required:"" help:"Link URL (or multiple comma separated) to Crossplane ${kind} to be applied to Environment."`
+	Wait    bool          `optional:"" short:"w" help:"Wait until ${kind} is healthy."`
+	Timeout time.Duration `optional:"" short:"t" help:"How much to wait until ${kind} is healthy, without limit if empty (valid time units are ns, us, ms, s, m, h)."`
+}
+
+func (c *ApplyCmd) Run(ctx context.Context, kctx *kong.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(kctx.Selected().Vars()["kind"])
+	if err != nil {
+		return err
+	}
+	return install(ctx, config, dc, pkg, strings.Split(c.Link, ","), c.Wait, c.Timeout, logger)
+}
@@ -0,0 +1,78 @@
+package packages
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"go.uber.org/zap"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type buildCmd struct {
+	Kind         string     `required:"" help:"Kind of package: configuration, provider or function."`
+	Path         string     `arg:"" help:"Package directory."`
+	Output       string     `short:"o" help:"Path of package archive, named by package directory if empty."`
+	MainPath     string     `help:"Path to main module relative to package directory, for providers and functions."`
+	ExamplesRoot string     `help:"Directory of examples, examples directory of package if empty."`
+	RuntimeImage string     `help:"Runtime image embedded into provider or function instead of binary built from main module, like docker-daemon://provider:v0.1.0."`
+	BuildFlags   BuildFlags `embed:""`
+}
+
+func (c *buildCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	index, err := pkg.Build(ctx, xpkg.BuildOptions{
+		Path:         c.Path,
+		MainPath:     c.MainPath,
+		ExamplesPath: c.ExamplesRoot,
+		RuntimeImage: c.RuntimeImage,
+		Build:        c.BuildFlags.Config(),
+	}, logger)
+	if err != nil {
+		return err
+	}
+
+	output := c.Output
+	if output == "" {
+		abs, err := filepath.Abs(c.Path)
+		if err != nil {
+			return err
+		}
+		output = filepath.Base(abs) + ".xpkg"
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range manifest.Manifests {
+		img, err := index.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		file := output
+		if len(manifest.Manifests) > 1 {
+			file = platformFile(output, desc.Platform)
+		}
+		if err := tarball.WriteToFile(file, nil, img); err != nil {
+			return err
+		}
+		logger.Infof("%s package written to %s.", pkg.Kind(), file)
+	}
+	return nil
+}
+
+// Archive path of platform, like provider_linux_arm64.xpkg
+func platformFile(output string, platform *regv1.Platform) string {
+	ext := filepath.Ext(output)
+	parts := []string{strings.TrimSuffix(output, ext), platform.OS, platform.Architecture}
+	if platform.Variant != "" {
+		parts = append(parts, platform.Variant)
+	}
+	return strings.Join(parts, "_") + ext
+}
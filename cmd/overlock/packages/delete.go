@@ -0,0 +1,28 @@
+package packages
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type deleteCmd struct {
+	Kind string   `required:"" help:"Kind of package: configuration, provider or function."`
+	Refs []string `arg:"" required:"" help:"Package references or names of installed packages."`
+}
+
+func (c *deleteCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	for _, ref := range c.Refs {
+		if err := pkg.Delete(ctx, dc, ref, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
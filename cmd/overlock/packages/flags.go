@@ -0,0 +1,24 @@
+package packages
+
+import "github.com/web-seven/overlock/internal/build"
+
+// Flags of provider and function binary build, overriding build section of
+// project file in package directory
+type BuildFlags struct {
+	Platform     []string `optional:"" help:"Target platforms of binary, like linux/arm64, multi-platform package is built for several."`
+	Ldflags      string   `optional:"" help:"Go linker flags of binary build."`
+	Tags         []string `optional:"" help:"Go build tags of binary build."`
+	BuildEnv     []string `optional:"" help:"Environment variables of binary build, like GOPRIVATE=example.com."`
+	BuildCommand string   `optional:"" help:"Command building binary instead of go build, it writes binary to path of OUTPUT variable."`
+}
+
+// Build configuration set by flags
+func (f BuildFlags) Config() build.Config {
+	return build.Config{
+		Platforms: f.Platform,
+		LDFlags:   f.Ldflags,
+		Tags:      f.Tags,
+		Env:       f.BuildEnv,
+		Command:   f.BuildCommand,
+	}
+}
@@ -0,0 +1,47 @@
+package packages
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type historyCmd struct {
+	Kind string `required:"" help:"Kind of package: configuration, provider or function."`
+	Ref  string `arg:"" help:"Package reference or name of installed package."`
+}
+
+func (c *historyCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	revisions, err := pkg.Revisions(ctx, dc, c.Ref)
+	if err != nil {
+		return err
+	}
+
+	table := pterm.TableData{{"REVISION", "NAME", "IMAGE", "STATE", "HEALTHY", "AGE"}}
+	for _, rev := range revisions {
+		state := "Inactive"
+		if rev.Active {
+			state = "Active"
+		}
+		table = append(table, []string{
+			strconv.FormatInt(rev.Number, 10),
+			rev.Name,
+			rev.Image,
+			state,
+			strconv.FormatBool(rev.Healthy),
+			duration.HumanDuration(time.Since(rev.Created)),
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
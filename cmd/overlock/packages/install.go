@@ -0,0 +1,86 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/lock"
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type installCmd struct {
+	Kind     string        `optional:"" help:"Kind of package: configuration, provider or function. Required unless installed from lock file."`
+	Refs     []string      `arg:"" optional:"" help:"Package references, like xpkg.upbound.io/upbound/provider-aws-s3:v1.0.0."`
+	Locked   bool          `optional:"" help:"Install packages with dependencies pinned to digests of lock file, all locked packages if no references set."`
+	LockFile string        `optional:"" help:"Path to lock file." default:"overlock.lock"`
+	Wait     bool          `optional:"" short:"w" help:"Wait until packages are healthy."`
+	Timeout  time.Duration `optional:"" short:"t" help:"How much to wait until packages are healthy, without limit if empty (valid time units are ns, us, ms, s, m, h)."`
+}
+
+func (c *installCmd) Run(ctx context.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	if c.Locked {
+		return c.installLocked(ctx, config, dc, logger)
+	}
+	if c.Kind == "" || len(c.Refs) == 0 {
+		return fmt.Errorf("package kind and references are required")
+	}
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	return install(ctx, config, dc, pkg, c.Refs, c.Wait, c.Timeout, logger)
+}
+
+// Install packages of kind by references, waiting until they are healthy
+func install(ctx context.Context, config *rest.Config, dc *dynamic.DynamicClient, pkg xpkg.Package, refs []string, wait bool, timeout time.Duration, logger *zap.SugaredLogger) error {
+	for _, ref := range refs {
+		if err := pkg.Install(ctx, config, ref, xpkg.InstallOptions{}, logger); err != nil {
+			return err
+		}
+	}
+	if !wait {
+		return nil
+	}
+	return pkg.WaitHealthy(ctx, dc, refs, timeout, logger)
+}
+
+func (c *installCmd) installLocked(ctx context.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	l, err := lock.Load(c.LockFile)
+	if err != nil {
+		return err
+	}
+	locked, err := l.Find(c.Refs)
+	if err != nil {
+		return err
+	}
+	pkgs := []lock.Package{}
+	for _, pkg := range locked {
+		if c.Kind == "" || pkg.KindName() == c.Kind {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages to install found in lock file %s", c.LockFile)
+	}
+	if _, err := lock.Install(ctx, config, pkgs, logger); err != nil {
+		return err
+	}
+	if !c.Wait {
+		return nil
+	}
+	for _, pkg := range pkgs {
+		kind, err := xpkg.New(pkg.Kind)
+		if err != nil {
+			return err
+		}
+		if err := kind.WaitHealthy(ctx, dc, []string{pkg.Pinned()}, c.Timeout, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package packages
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type lintCmd struct {
+	Kind string `required:"" help:"Kind of package: configuration, provider or function."`
+	Path string `arg:"" help:"Package directory."`
+}
+
+func (c *lintCmd) Run(logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	diagnostics, err := pkg.Lint(c.Path)
+	if err != nil {
+		return err
+	}
+	for _, d := range diagnostics {
+		fmt.Println(d)
+	}
+	if xpkg.HasErrors(diagnostics) {
+		return fmt.Errorf("%s package %s has lint errors", pkg.Kind(), c.Path)
+	}
+	logger.Infof("%s package %s is valid.", pkg.Kind(), c.Path)
+	return nil
+}
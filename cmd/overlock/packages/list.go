@@ -0,0 +1,40 @@
+package packages
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type listCmd struct {
+	Kind string `optional:"" help:"Kind of package: configuration, provider or function, all kinds if empty."`
+}
+
+func (c *listCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	kinds := xpkg.Kinds()
+	if c.Kind != "" {
+		kinds = []string{c.Kind}
+	}
+
+	table := pterm.TableData{{"KIND", "NAME", "PACKAGE", "INSTALLED", "HEALTHY"}}
+	for _, kind := range kinds {
+		pkg, err := xpkg.New(kind)
+		if err != nil {
+			return err
+		}
+		installed, err := pkg.List(ctx, dc)
+		if err != nil {
+			logger.Debugf("Cannot list %s packages: %v", kind, err)
+			continue
+		}
+		for _, p := range installed {
+			table = append(table, []string{p.Kind, p.Name, p.Package, strconv.FormatBool(p.Installed), strconv.FormatBool(p.Healthy)})
+		}
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
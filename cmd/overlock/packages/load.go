@@ -0,0 +1,70 @@
+package packages
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type loadCmd struct {
+	Kind string `required:"" help:"Kind of package: configuration, provider or function."`
+
+	LoadCmd `embed:"" set:"kind=package"`
+}
+
+func (c *loadCmd) Run(ctx context.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	return c.load(ctx, c.Kind, config, dc, logger)
+}
+
+// Load command of package kind, set with kind variable of parent command,
+// like set:"kind=provider"
+type LoadCmd struct {
+	Name     string        `arg:"" help:"Package reference in local registry, like ${kind}-example:v0.1.0."`
+	Path     string        `help:"Path to ${kind} archive or package directory."`
+	Stdin    bool          `help:"Load ${kind} archive from STDIN."`
+	MainPath string        `help:"Path to main module relative to package directory, for providers and functions."`
+	Apply    bool          `help:"Install ${kind} after load."`
+	Upgrade  bool          `help:"Upgrade patch version of installed ${kind}."`
+	SignKey  string        `help:"Path to private key used to sign package in local registry."`
+	Wait     bool          `optional:"" short:"w" help:"Wait until applied ${kind} is healthy."`
+	Timeout  time.Duration `optional:"" short:"t" help:"How much to wait until ${kind} is healthy, without limit if empty (valid time units are ns, us, ms, s, m, h)."`
+
+	BuildFlags BuildFlags `embed:""`
+}
+
+func (c *LoadCmd) Run(ctx context.Context, kctx *kong.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	return c.load(ctx, kctx.Selected().Vars()["kind"], config, dc, logger)
+}
+
+func (c *LoadCmd) load(ctx context.Context, kind string, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(kind)
+	if err != nil {
+		return err
+	}
+	opts := xpkg.LoadOptions{
+		Name:     c.Name,
+		Path:     c.Path,
+		Upgrade:  c.Upgrade,
+		SignKey:  c.SignKey,
+		MainPath: c.MainPath,
+		Build:    c.BuildFlags.Config(),
+	}
+	if c.Stdin {
+		opts.Archive = os.Stdin
+	}
+	ref, err := pkg.Load(ctx, config, dc, opts, logger)
+	if err != nil {
+		return err
+	}
+	if !c.Apply {
+		return nil
+	}
+	return install(ctx, config, dc, pkg, []string{ref}, c.Wait, c.Timeout, logger)
+}
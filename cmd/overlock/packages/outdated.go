@@ -0,0 +1,65 @@
+package packages
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/pterm/pterm"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+	"github.com/web-seven/overlock/pkg/registry"
+)
+
+type outdatedCmd struct {
+	Kind       string `optional:"" help:"Kind of package: configuration, provider or function, all kinds if empty."`
+	Constraint string `optional:"" help:"Semantic version constraint of newest version, like ~1.2, latest version if empty."`
+}
+
+func (c *outdatedCmd) Run(ctx context.Context, client *kubernetes.Clientset, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	updates, err := installedUpdates(ctx, client, dc, c.Kind, c.Constraint, logger)
+	if err != nil {
+		return err
+	}
+
+	table := pterm.TableData{{"KIND", "NAME", "REPOSITORY", "CURRENT", "LATEST", "OUTDATED"}}
+	for _, u := range updates {
+		latest := u.Latest
+		if u.Err != nil {
+			logger.Debugf("Cannot resolve newest version of %s %s: %v", u.Kind, u.Name, u.Err)
+			latest = "unknown"
+		}
+		table = append(table, []string{u.Kind, u.Name, u.Repository, u.Current, latest, strconv.FormatBool(u.Available())})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
+
+// Updates of installed packages of kind, all kinds if empty, tags are listed
+// with credentials of registries configured in cluster
+func installedUpdates(ctx context.Context, client *kubernetes.Clientset, dc dynamic.Interface, kind string, constraint string, logger *zap.SugaredLogger) ([]xpkg.Update, error) {
+	kinds := xpkg.Kinds()
+	if kind != "" {
+		kinds = []string{kind}
+	}
+	installed := []xpkg.Installed{}
+	for _, kind := range kinds {
+		pkg, err := xpkg.New(kind)
+		if err != nil {
+			return nil, err
+		}
+		list, err := pkg.List(ctx, dc)
+		if err != nil {
+			logger.Debugf("Cannot list %s packages: %v", kind, err)
+			continue
+		}
+		installed = append(installed, list...)
+	}
+	keychain, err := registry.Keychain(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return xpkg.Updates(installed, constraint, crane.WithContext(ctx), crane.WithAuthFromKeychain(keychain)), nil
+}
@@ -0,0 +1,16 @@
+package packages
+
+type Cmd struct {
+	Install  installCmd  `cmd:"" help:"Install Crossplane packages."`
+	List     listCmd     `cmd:"" help:"List installed Crossplane packages."`
+	Load     loadCmd     `cmd:"" help:"Load Crossplane package from archive, STDIN or directory."`
+	Serve    serveCmd    `cmd:"" help:"Watch changes of package directory, build, load and install."`
+	Build    buildCmd    `cmd:"" help:"Build Crossplane package archive from directory without pushing."`
+	Lint     lintCmd     `cmd:"" help:"Validate Crossplane package directory."`
+	Push     pushCmd     `cmd:"" help:"Build and push Crossplane package to remote registry."`
+	Delete   deleteCmd   `cmd:"" help:"Delete Crossplane packages."`
+	History  historyCmd  `cmd:"" help:"List revisions of installed Crossplane package."`
+	Rollback rollbackCmd `cmd:"" help:"Activate previous revision of installed Crossplane package."`
+	Outdated outdatedCmd `cmd:"" help:"List installed Crossplane packages with newest versions in remote registries."`
+	Upgrade  upgradeCmd  `cmd:"" help:"Upgrade installed Crossplane packages to newest versions in remote registries."`
+}
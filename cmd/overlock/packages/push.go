@@ -0,0 +1,106 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"go.uber.org/zap"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/loader"
+	"github.com/web-seven/overlock/internal/xpkg"
+	"github.com/web-seven/overlock/pkg/registry"
+)
+
+type pushCmd struct {
+	Source       string     `arg:"" help:"Package directory or package archive."`
+	Ref          string     `arg:"" help:"Reference of package in remote registry, like ghcr.io/org/provider-example:v0.1.0."`
+	Kind         string     `optional:"" help:"Kind of package built from directory: configuration, provider or function."`
+	Registry     string     `optional:"" help:"Name of registry with credentials for push, Docker config credentials are used if empty."`
+	MainPath     string     `help:"Path to main module relative to package directory, for providers and functions."`
+	ExamplesRoot string     `help:"Directory of examples, examples directory of package if empty."`
+	RuntimeImage string     `help:"Runtime image embedded into provider or function instead of binary built from main module."`
+	BuildFlags   BuildFlags `embed:""`
+}
+
+func (c *pushCmd) Run(ctx context.Context, logger *zap.SugaredLogger) error {
+	ref, err := name.ParseReference(c.Ref)
+	if err != nil {
+		return err
+	}
+	img, index, err := c.image(ctx, logger)
+	if err != nil {
+		return err
+	}
+
+	auth, err := c.auth(ctx, ref)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Pushing to %s", ref)
+	var pushed string
+	if img != nil {
+		pushed, err = registry.PushRemote(ctx, ref, img, auth)
+	} else {
+		pushed, err = registry.PushRemoteIndex(ctx, ref, index, auth)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Infof("Package %s pushed as %s.", c.Ref, pushed)
+	return nil
+}
+
+// Package image read from archive or built from directory, index is set
+// instead of image for multi-platform package
+func (c *pushCmd) image(ctx context.Context, logger *zap.SugaredLogger) (regv1.Image, regv1.ImageIndex, error) {
+	fi, err := os.Stat(c.Source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !fi.IsDir() {
+		img, err := loader.LoadPathArchive(c.Source)
+		return img, nil, err
+	}
+	if c.Kind == "" {
+		return nil, nil, fmt.Errorf("package kind is required to build package from directory")
+	}
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := pkg.Build(ctx, xpkg.BuildOptions{
+		Path:         c.Source,
+		MainPath:     c.MainPath,
+		ExamplesPath: c.ExamplesRoot,
+		RuntimeImage: c.RuntimeImage,
+		Build:        c.BuildFlags.Config(),
+	}, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, err := xpkg.SingleImage(index)
+	return img, index, err
+}
+
+// Authenticator of remote registry, cluster is only required for registry
+// secrets, so packages could be pushed from CI without cluster
+func (c *pushCmd) auth(ctx context.Context, ref name.Reference) (authn.Authenticator, error) {
+	if c.Registry == "" {
+		return registry.RemoteAuth(ctx, nil, "", ref)
+	}
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kube.Client(config)
+	if err != nil {
+		return nil, err
+	}
+	return registry.RemoteAuth(ctx, client, c.Registry, ref)
+}
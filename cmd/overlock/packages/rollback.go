@@ -0,0 +1,57 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type rollbackCmd struct {
+	Kind       string        `required:"" help:"Kind of package: configuration, provider or function."`
+	Ref        string        `arg:"" help:"Package reference or name of installed package."`
+	ToRevision int64         `optional:"" name:"to-revision" help:"Revision number to activate, revision before active one if empty."`
+	Wait       bool          `optional:"" short:"w" help:"Wait until package is healthy."`
+	Timeout    time.Duration `optional:"" short:"t" help:"How much to wait until package is healthy, without limit if empty (valid time units are ns, us, ms, s, m, h)."`
+}
+
+func (c *rollbackCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	name, err := pkg.Name(ctx, dc, c.Ref)
+	if err != nil {
+		return err
+	}
+	target, err := pkg.Rollback(ctx, dc, name, c.ToRevision, logger)
+	if err != nil {
+		return err
+	}
+	if !c.Wait {
+		return nil
+	}
+	if err := pkg.WaitHealthy(ctx, dc, []string{name}, c.Timeout, logger); err != nil {
+		return err
+	}
+
+	revisions, err := pkg.Revisions(ctx, dc, name)
+	if err != nil {
+		return err
+	}
+	for _, rev := range revisions {
+		if rev.Name != target.Name {
+			continue
+		}
+		if !rev.Active || !rev.Healthy {
+			return fmt.Errorf("revision %s of %s %s is not active and healthy", rev.Name, pkg.Kind(), name)
+		}
+		logger.Infof("Revision %s of %s %s is active and healthy.", rev.Name, pkg.Kind(), name)
+		return nil
+	}
+	return fmt.Errorf("revision %s of %s %s not found", target.Name, pkg.Kind(), name)
+}
@@ -0,0 +1,31 @@
+package packages
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type serveCmd struct {
+	Kind     string `required:"" help:"Kind of package: configuration, provider or function."`
+	Path     string `default:"./" arg:"" help:"Path to package directory."`
+	MainPath string `help:"Path to main module relative to package directory, for providers and functions."`
+
+	BuildFlags BuildFlags `embed:""`
+}
+
+func (c *serveCmd) Run(ctx context.Context, config *rest.Config, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(c.Kind)
+	if err != nil {
+		return err
+	}
+	return pkg.Serve(ctx, config, dc, xpkg.ServeOptions{
+		Path:     c.Path,
+		MainPath: c.MainPath,
+		Build:    c.BuildFlags.Config(),
+	}, logger)
+}
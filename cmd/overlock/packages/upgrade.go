@@ -0,0 +1,80 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type upgradeCmd struct {
+	Kind       string        `optional:"" help:"Kind of package: configuration, provider or function, all kinds if empty."`
+	Names      []string      `arg:"" optional:"" help:"Names of installed packages to upgrade."`
+	All        bool          `optional:"" help:"Upgrade all outdated packages."`
+	Constraint string        `optional:"" help:"Semantic version constraint of newest version, like ~1.2, latest version if empty."`
+	Wait       bool          `optional:"" short:"w" help:"Wait until upgraded packages are healthy."`
+	Timeout    time.Duration `optional:"" short:"t" help:"How much to wait until packages are healthy, without limit if empty (valid time units are ns, us, ms, s, m, h)."`
+}
+
+func (c *upgradeCmd) Run(ctx context.Context, client *kubernetes.Clientset, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	if c.All == (len(c.Names) > 0) {
+		return fmt.Errorf("set names of packages or --all")
+	}
+	updates, err := installedUpdates(ctx, client, dc, c.Kind, c.Constraint, logger)
+	if err != nil {
+		return err
+	}
+
+	upgraded := map[string][]string{}
+	found := map[string]bool{}
+	for _, u := range updates {
+		if !c.All && !slices.Contains(c.Names, u.Name) {
+			continue
+		}
+		found[u.Name] = true
+		if u.Err != nil {
+			if !c.All {
+				return fmt.Errorf("cannot resolve newest version of %s %s: %w", u.Kind, u.Name, u.Err)
+			}
+			logger.Debugf("Cannot resolve newest version of %s %s: %v", u.Kind, u.Name, u.Err)
+			continue
+		}
+		if !u.Available() {
+			logger.Infof("%s %s is up to date (%s).", u.Kind, u.Name, u.Current)
+			continue
+		}
+		pkg, err := xpkg.New(u.Kind)
+		if err != nil {
+			return err
+		}
+		if err := pkg.Upgrade(ctx, dc, u.Name, u.Reference(), logger); err != nil {
+			return err
+		}
+		upgraded[u.Kind] = append(upgraded[u.Kind], u.Name)
+	}
+	for _, name := range c.Names {
+		if !found[name] {
+			return fmt.Errorf("package %s not found", name)
+		}
+	}
+
+	if !c.Wait {
+		return nil
+	}
+	for kind, names := range upgraded {
+		pkg, err := xpkg.New(kind)
+		if err != nil {
+			return err
+		}
+		if err := pkg.WaitHealthy(ctx, dc, names, c.Timeout, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/web-seven/overlock/internal/credentials"
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+type credentialsCmd struct {
+	Provider   string `arg:"" help:"Name or reference of installed Provider."`
+	FromFile   string `required:"" type:"path" help:"Credentials file, like ~/.aws/credentials, GCP service account key, Azure JSON, kubeconfig or KEY=VALUE lines."`
+	Profile    string `optional:"" help:"Profile of AWS credentials or context of kubeconfig."`
+	Format     string `optional:"" enum:",aws,gcp,azure,kubeconfig,generic" default:"" help:"Format of credentials file, detected from Provider package when empty."`
+	APIVersion string `optional:"" name:"api-version" help:"API version of ProviderConfig, required for Providers without template."`
+	ConfigName string `optional:"" name:"config-name" default:"default" help:"Name of ProviderConfig."`
+	SecretName string `optional:"" name:"secret-name" help:"Name of credentials Secret, defaults to <provider>-credentials."`
+	ProjectID  string `optional:"" name:"project-id" help:"GCP project, taken from service account key when empty."`
+}
+
+func (c *credentialsCmd) Run(ctx context.Context, client *kubernetes.Clientset, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New("provider")
+	if err != nil {
+		return err
+	}
+	name, err := pkg.Name(ctx, dc, c.Provider)
+	if err != nil {
+		return err
+	}
+	installed, err := pkg.List(ctx, dc)
+	if err != nil {
+		return err
+	}
+	source := ""
+	for _, p := range installed {
+		if p.Name == name {
+			source = p.Package
+		}
+	}
+	if source == "" {
+		return fmt.Errorf("provider %s has no package", c.Provider)
+	}
+
+	return credentials.Bootstrap(ctx, client, dc, name, source, credentials.Options{
+		File:       c.FromFile,
+		Profile:    c.Profile,
+		Format:     c.Format,
+		APIVersion: c.APIVersion,
+		ConfigName: c.ConfigName,
+		SecretName: c.SecretName,
+		ProjectID:  c.ProjectID,
+	}, logger)
+}
@@ -2,20 +2,33 @@ package provider
 
 import (
 	"context"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/web-seven/overlock/internal/provider"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
 
 type installCmd struct {
-	ProviderUrl string `arg:"" required:"" help:"Provider URL to Crossplane provider to be installed to Environment."`
+	ProviderUrl string        `arg:"" required:"" help:"Provider URL to Crossplane provider to be installed to Environment."`
+	Wait        bool          `optional:"" short:"w" help:"Wait until provider is healthy."`
+	Timeout     time.Duration `optional:"" short:"t" help:"Timeout is used to set how much to wait until provider is healthy (valid time units are ns, us, ms, s, m, h)"`
 }
 
 func (c *installCmd) Run(ctx context.Context, config *rest.Config, dynamicClient *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	provider.InstallProvider(c.ProviderUrl, config, logger)
+	if err := provider.InstallProvider(c.ProviderUrl, config, logger); err != nil {
+		return err
+	}
+	if !c.Wait {
+		return nil
+	}
 
-	return nil
+	pkg, err := xpkg.New("provider")
+	if err != nil {
+		return err
+	}
+	return pkg.WaitHealthy(ctx, dynamicClient, []string{c.ProviderUrl}, c.Timeout, logger)
 }
@@ -6,7 +6,7 @@ import (
 	"github.com/pterm/pterm"
 	"go.uber.org/zap"
 
-	"github.com/web-seven/overlock/internal/provider"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
@@ -15,10 +15,17 @@ type listCmd struct {
 }
 
 func (c *listCmd) Run(ctx context.Context, config *rest.Config, dynamicClient *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-	providers := provider.ListProviders(ctx, dynamicClient, logger)
+	pkg, err := xpkg.New("provider")
+	if err != nil {
+		return err
+	}
+	providers, err := pkg.List(ctx, dynamicClient)
+	if err != nil {
+		return err
+	}
 	table := pterm.TableData{[]string{"NAME", "PACKAGE"}}
 	for _, provider := range providers {
-		table = append(table, []string{provider.Name, provider.Spec.Package})
+		table = append(table, []string{provider.Name, provider.Package})
 	}
 	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
 	return nil
@@ -1,9 +1,16 @@
 package provider
 
+import (
+	"github.com/web-seven/overlock/cmd/overlock/packages"
+	"github.com/web-seven/overlock/cmd/overlock/runtimeconfig"
+)
+
 type Cmd struct {
-	Install installCmd `cmd:"" help:"Install Crossplane Provider."`
-	List    listCmd    `cmd:"" help:"List all Crossplane Providers."`
-	Load    loadCmd    `cmd:"" help:"Load Crossplane Provider."`
-	Serve   serveCmd   `cmd:"" help:"Watch changes of Provider, build and load."`
-	Delete  deleteCmd  `cmd:"" help:"Delete Crossplane Provider."`
+	Install       installCmd        `cmd:"" help:"Install Crossplane Provider."`
+	List          listCmd           `cmd:"" help:"List all Crossplane Providers."`
+	Load          packages.LoadCmd  `cmd:"" set:"kind=provider" help:"Load Crossplane Provider."`
+	Serve         serveCmd          `cmd:"" help:"Watch changes of Provider, build and load."`
+	Delete        deleteCmd         `cmd:"" help:"Delete Crossplane Provider."`
+	RuntimeConfig runtimeconfig.Cmd `cmd:"" name:"runtime-config" set:"kind=provider" help:"Manage DeploymentRuntimeConfig of Provider."`
+	Credentials   credentialsCmd    `cmd:"" help:"Create credentials Secret and ProviderConfig of Provider."`
 }
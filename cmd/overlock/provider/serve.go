@@ -5,7 +5,8 @@ import (
 
 	"go.uber.org/zap"
 
-	"github.com/web-seven/overlock/internal/provider"
+	"github.com/web-seven/overlock/cmd/overlock/packages"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 )
@@ -13,8 +14,14 @@ import (
 type serveCmd struct {
 	Path     string `default:"./" arg:"" help:"Path to package directory"`
 	MainPath string `default:"cmd/provider" arg:"" help:"Path to main module"`
+
+	BuildFlags packages.BuildFlags `embed:""`
 }
 
 func (c *serveCmd) Run(ctx context.Context, dc *dynamic.DynamicClient, config *rest.Config, logger *zap.SugaredLogger) error {
-	return provider.Serve(ctx, dc, config, logger, c.Path, c.MainPath)
+	pkg, err := xpkg.New("provider")
+	if err != nil {
+		return err
+	}
+	return pkg.Serve(ctx, config, dc, xpkg.ServeOptions{Path: c.Path, MainPath: c.MainPath, Build: c.BuildFlags.Config()}, logger)
 }
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/web-seven/overlock/pkg/registry"
 	"go.uber.org/zap"
@@ -15,19 +17,49 @@ const (
 )
 
 type createCmd struct {
-	RegistryServer string `help:"is your Private Registry FQDN."`
-	Username       string `help:"is your Username."`
-	Password       string `help:"is your Password."`
-	Email          string `help:"is your Email."`
-	Default        bool   `help:"Set registry as default."`
-	Local          bool   `help:"Create local registry."`
-	Context        string `short:"c" help:"Kubernetes context where registry will be created."`
+	Server           string            `arg:"" optional:"" help:"Registry server to import credentials for, used with --from-docker-config."`
+	RegistryServer   string            `help:"is your Private Registry FQDN."`
+	Username         string            `help:"is your Username."`
+	Password         string            `help:"is your Password."`
+	PasswordStdin    bool              `help:"Read password from STDIN."`
+	Email            string            `help:"is your Email."`
+	FromDockerConfig bool              `help:"Import credentials from Docker config file and credential helpers."`
+	Default          bool              `help:"Set registry as default."`
+	Local            bool              `help:"Create local registry."`
+	StorageSize      string            `help:"Size of volume claim for local registry data." default:"10Gi"`
+	StorageClass     string            `help:"Storage class of volume claim for local registry data, cluster default if empty."`
+	HostPath         string            `help:"Store local registry data in node host path instead of volume claim, suitable for kind and k3d."`
+	Proxy            string            `help:"Upstream registry URL, local registry works as pull-through cache of it."`
+	ProxyRegistry    string            `help:"Name of existing registry which credentials are used for upstream, matched by server if empty."`
+	PolicyNamespaces []string          `help:"Namespaces where images of local registry are rewritten, all if empty."`
+	PolicyLabels     map[string]string `help:"Labels of pods where images of local registry are rewritten, e.g. --policy-labels=team=dev."`
+	Context          string            `short:"c" help:"Kubernetes context where registry will be created."`
 }
 
 func (c *createCmd) Run(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, logger *zap.SugaredLogger) error {
+	if err := c.resolveCredentials(); err != nil {
+		return err
+	}
+
 	reg := registry.New(c.RegistryServer, c.Username, c.Password, c.Email)
 	if c.Local {
 		reg = registry.NewLocal()
+		reg.WithStorage(c.StorageSize, c.StorageClass, c.HostPath)
+		reg.WithPolicyScope(c.PolicyNamespaces, c.PolicyLabels)
+	}
+	if c.Proxy != "" {
+		if !c.Local {
+			return fmt.Errorf("proxy could be configured only for local registry")
+		}
+		creds, err := registry.ProxyCredentials(ctx, client, c.Proxy, c.ProxyRegistry)
+		if err != nil {
+			return err
+		}
+		if creds == nil {
+			logger.Debugf("No credentials found for %s, upstream will be accessed anonymously", c.Proxy)
+		}
+		reg.WithProxy(c.Proxy, creds)
+		logger.Warn("Local registry in proxy mode is read-only, packages could not be loaded into it.")
 	}
 	reg.SetDefault(c.Default)
 	reg.SetLocal(c.Local)
@@ -51,3 +83,41 @@ func (c *createCmd) Run(ctx context.Context, client *kubernetes.Clientset, confi
 	}
 	return nil
 }
+
+// Fill credentials from Docker config or STDIN when requested
+func (c *createCmd) resolveCredentials() error {
+	if c.Local {
+		return nil
+	}
+	if c.PasswordStdin {
+		password, err := registry.PasswordFromReader(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read password from STDIN: %w", err)
+		}
+		c.Password = password
+	}
+	if !c.FromDockerConfig {
+		return nil
+	}
+
+	server := c.Server
+	if server == "" {
+		server = c.RegistryServer
+	}
+	creds, err := registry.CredentialsFromDockerConfig(server)
+	if err != nil {
+		return err
+	}
+	// Explicitly set flags take precedence over Docker config
+	merged, err := registry.Credentials{
+		Server:   c.RegistryServer,
+		Username: c.Username,
+		Password: c.Password,
+		Email:    c.Email,
+	}.Merge(*creds)
+	if err != nil {
+		return err
+	}
+	c.RegistryServer, c.Username, c.Password, c.Email = merged.Server, merged.Username, merged.Password, merged.Email
+	return nil
+}
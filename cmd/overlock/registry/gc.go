@@ -0,0 +1,32 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+type gcCmd struct {
+	KeepPatches int  `default:"3" help:"Number of last patch versions to keep for every minor version."`
+	DryRun      bool `help:"Only print tags which would be deleted."`
+}
+
+func (c *gcCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	deleted, err := registry.GarbageCollectLocal(ctx, c.KeepPatches, c.DryRun, config, logger)
+	if err != nil {
+		return err
+	}
+	for _, ref := range deleted {
+		if c.DryRun {
+			logger.Infof("Would delete %s", ref)
+		} else {
+			logger.Infof("Deleted %s", ref)
+		}
+	}
+	if !c.DryRun {
+		logger.Info("Local registry garbage collected.")
+	}
+	return nil
+}
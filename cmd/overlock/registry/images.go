@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+type imagesCmd struct {
+	List    imagesListCmd    `cmd:"" help:"List images of local registry"`
+	Delete  imagesDeleteCmd  `cmd:"" help:"Delete image from local registry"`
+	Inspect imagesInspectCmd `cmd:"" help:"Inspect image of local registry"`
+	Verify  imagesVerifyCmd  `cmd:"" help:"Verify signature of image in local registry"`
+}
+
+type imagesListCmd struct {
+	Repository string `arg:"" optional:"" help:"Repository to list tags for, all repositories if empty."`
+}
+
+func (c *imagesListCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	images, err := registry.ListLocalImages(ctx, c.Repository, config, logger)
+	if err != nil {
+		return err
+	}
+
+	tableImages := pterm.TableData{
+		[]string{"REPOSITORY", "TAGS"},
+	}
+	for _, img := range images {
+		tableImages = append(tableImages, []string{
+			img.Repository,
+			strings.Join(img.Tags, ", "),
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(tableImages).Render()
+}
+
+type imagesDeleteCmd struct {
+	Reference string `arg:"" required:"" help:"Image reference as repository:tag or repository@digest."`
+}
+
+func (c *imagesDeleteCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	err := registry.DeleteLocalImage(ctx, c.Reference, config, logger)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Image %s deleted, run 'overlock registry gc' to free storage.", c.Reference)
+	return nil
+}
+
+type imagesInspectCmd struct {
+	Reference string `arg:"" required:"" help:"Image reference as repository:tag or repository@digest."`
+}
+
+func (c *imagesInspectCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	details, err := registry.InspectLocalImage(ctx, c.Reference, config, logger)
+	if err != nil {
+		return err
+	}
+
+	tableDetails := pterm.TableData{
+		{"Reference", details.Reference},
+		{"Digest", details.Digest},
+		{"Media type", details.MediaType},
+		{"Size", fmt.Sprint(details.Size)},
+		{"Platforms", strings.Join(details.Platforms, ", ")},
+	}
+	if !details.Created.IsZero() {
+		tableDetails = append(tableDetails, []string{"Created", details.Created.String()})
+	}
+	if details.Layers > 0 {
+		tableDetails = append(tableDetails, []string{"Layers", fmt.Sprint(details.Layers)})
+	}
+	keys := []string{}
+	for k := range details.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tableDetails = append(tableDetails, []string{k, details.Annotations[k]})
+	}
+	return pterm.DefaultTable.WithData(tableDetails).Render()
+}
+
+type imagesVerifyCmd struct {
+	Reference string `arg:"" required:"" help:"Image reference as repository:tag or repository@digest."`
+	Key       string `required:"" help:"Path to public key."`
+}
+
+func (c *imagesVerifyCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	err := registry.VerifyLocalImage(ctx, c.Reference, c.Key, config, logger)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Signature of image %s verified.", c.Reference)
+	return nil
+}
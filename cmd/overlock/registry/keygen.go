@@ -0,0 +1,20 @@
+package registry
+
+import (
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+)
+
+type keygenCmd struct {
+	OutputKey string `default:"overlock.key" help:"Path of private key used to sign packages."`
+	OutputPub string `default:"overlock.pub" help:"Path of public key used to verify packages."`
+}
+
+func (c *keygenCmd) Run(logger *zap.SugaredLogger) error {
+	err := registry.GenerateSigningKey(c.OutputKey, c.OutputPub)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Private key written to %s, public key written to %s.", c.OutputKey, c.OutputPub)
+	return nil
+}
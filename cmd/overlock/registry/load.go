@@ -15,6 +15,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
+	"github.com/web-seven/overlock/internal/loader"
 	"github.com/web-seven/overlock/pkg/registry"
 )
 
@@ -31,7 +32,7 @@ const (
 
 type loadImageCmd struct {
 	Registry string `arg:"" help:"Name of the registry to load the image to."`
-	Path     string `arg:"" help:"Path to OCI image TAR archive."`
+	Path     string `arg:"" help:"Image source: archive path, oci-layout://dir, docker-archive://path or docker-daemon://image:tag."`
 	Name     string `required:"" short:"i" help:"Image name and tag (e.g., my-image:1.0)."`
 	Upgrade  bool   `help:"Upgrade patch version if image exists."`
 	Helm     bool   `help:"Add Helm chart OCI manifest layers with proper media types."`
@@ -54,15 +55,6 @@ func (c *loadImageCmd) Run(ctx context.Context, client *kubernetes.Clientset, co
 		}
 	}
 
-	var image regv1.Image
-
-	// Always create OCI image from empty base with archive as layer
-	logger.Debug("Creating OCI image from empty base")
-	image, err = createOCIImage(c.Path, c.Helm)
-	if err != nil {
-		return fmt.Errorf("failed to create OCI image: %w", err)
-	}
-
 	imageName := c.Name
 	if c.Upgrade {
 		logger.Debug("Upgrading image version")
@@ -72,9 +64,30 @@ func (c *loadImageCmd) Run(ctx context.Context, client *kubernetes.Clientset, co
 		}
 	}
 
-	// Push to local registry
+	// Image archives are pushed as is, keeping all platforms
+	var index regv1.ImageIndex
+	if !c.Helm {
+		var cleanup func()
+		index, cleanup, err = loader.LoadSource(ctx, c.Path)
+		defer cleanup()
+		if err != nil {
+			return fmt.Errorf("failed to load image archive: %w", err)
+		}
+	}
+
 	logger.Debugf("Pushing image to local registry as: %s", imageName)
-	err = registry.PushLocalRegistry(ctx, imageName, image, config, logger)
+	if index != nil {
+		err = pushIndex(ctx, imageName, index, config, logger)
+	} else {
+		// Create OCI image from empty base with archive as layer
+		logger.Debug("Creating OCI image from empty base")
+		var image regv1.Image
+		image, err = createOCIImage(c.Path, c.Helm)
+		if err != nil {
+			return fmt.Errorf("failed to create OCI image: %w", err)
+		}
+		err = registry.PushLocalRegistry(ctx, imageName, image, config, logger)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to push image to registry: %w", err)
 	}
@@ -133,6 +146,39 @@ func (c *loadImageCmd) upgradeImageVersion(ctx context.Context, config *rest.Con
 	return strings.Join([]string{pRef.Context().Name(), newVersion.String()}, tagDelim), nil
 }
 
+// pushIndex pushes single image of index as image and several images as index
+func pushIndex(ctx context.Context, imageName string, index regv1.ImageIndex, config *rest.Config, logger *zap.SugaredLogger) error {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range manifest.Manifests {
+		if desc.Platform != nil {
+			logger.Debugf("Found image %s for platform %s", desc.Digest, desc.Platform)
+		}
+	}
+	if len(manifest.Manifests) != 1 {
+		return registry.PushLocalRegistryIndex(ctx, imageName, index, config, logger)
+	}
+
+	desc := manifest.Manifests[0]
+	switch {
+	case desc.MediaType.IsIndex():
+		child, err := index.ImageIndex(desc.Digest)
+		if err != nil {
+			return err
+		}
+		return pushIndex(ctx, imageName, child, config, logger)
+	case desc.MediaType.IsImage():
+		img, err := index.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+		return registry.PushLocalRegistry(ctx, imageName, img, config, logger)
+	}
+	return fmt.Errorf("unsupported manifest media type %s", desc.MediaType)
+}
+
 // Image wraps regv1.Image for registry operations
 type Image struct {
 	regv1.Image
@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type mirrorCmd struct {
+	Package string `arg:"" required:"" help:"Package reference, version could be a constraint like v0.x."`
+	Rewrite bool   `help:"Rewrite dependencies of mirrored packages to reference local registry."`
+}
+
+func (c *mirrorCmd) Run(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, logger *zap.SugaredLogger) error {
+	isLocal, err := registry.IsLocalRegistry(ctx, client)
+	if !isLocal || err != nil {
+		reg := registry.NewLocal()
+		reg.SetDefault(true)
+		err := reg.Create(ctx, config, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	mirrored, err := registry.Mirror(ctx, c.Package, c.Rewrite, config, logger)
+	if err != nil {
+		return err
+	}
+	for _, ref := range mirrored {
+		logger.Infof("Mirrored %s", ref)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/web-seven/overlock/internal/policy"
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+type policyCmd struct {
+	Show policyShowCmd `cmd:"" help:"Print effective policy of registry"`
+}
+
+type policyShowCmd struct {
+	Name string `arg:"" optional:"" help:"Name of registry, local registry if empty."`
+}
+
+func (c *policyShowCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	name := c.Name
+	if name == "" {
+		name = registry.LocalRegistryName
+	}
+	plc, err := policy.GetRegistryPolicy(ctx, config, name)
+	if kerrors.IsNotFound(err) {
+		return fmt.Errorf("policy of registry %s not found", name)
+	}
+	if err != nil {
+		return err
+	}
+	plc.SetManagedFields(nil)
+	out, err := yaml.Marshal(plc.Object)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
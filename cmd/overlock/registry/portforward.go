@@ -0,0 +1,34 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+type portForwardCmd struct {
+	Port    int    `short:"p" default:"5000" help:"Local port to listen on, random if 0."`
+	Address string `default:"localhost" help:"Local address to listen on."`
+}
+
+func (c *portForwardCmd) Run(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	tunnel, err := registry.OpenTunnel(ctx, config, c.Address, c.Port, logger)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	logger.Infof("Local registry is available on %s, press Ctrl+C to stop.", tunnel.Host())
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-tunnel.Done():
+		if err := tunnel.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("local registry tunnel closed")
+	}
+}
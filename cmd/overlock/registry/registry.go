@@ -9,10 +9,18 @@ import (
 )
 
 type Cmd struct {
-	Create    createCmd    `cmd:"" help:"Create registry"`
-	List      listCmd      `cmd:"" help:"List registries"`
-	Delete    deleteCmd    `cmd:"" help:"Delete registry"`
-	LoadImage loadImageCmd `cmd:"" name:"load-image" help:"Load OCI image to registry"`
+	Create      createCmd      `cmd:"" help:"Create registry"`
+	List        listCmd        `cmd:"" help:"List registries"`
+	Delete      deleteCmd      `cmd:"" help:"Delete registry"`
+	Update      updateCmd      `cmd:"" help:"Update registry credentials in place"`
+	Rotate      rotateCmd      `cmd:"" help:"Rotate registry credentials from file, environment or Docker credential helper"`
+	LoadImage   loadImageCmd   `cmd:"" name:"load-image" help:"Load OCI image to registry"`
+	Images      imagesCmd      `cmd:"" help:"Manage images of local registry"`
+	Mirror      mirrorCmd      `cmd:"" help:"Mirror package with dependencies to local registry"`
+	PortForward portForwardCmd `cmd:"" name:"port-forward" help:"Forward local port to local registry"`
+	Gc          gcCmd          `cmd:"" name:"gc" help:"Delete old patch versions and garbage collect local registry"`
+	Keygen      keygenCmd      `cmd:"" help:"Generate key pair for signing packages of local registry"`
+	Policy      policyCmd      `cmd:"" help:"Inspect policy rewriting images of local registry"`
 }
 
 func Predictors(ctx context.Context, client *kubernetes.Clientset) map[string]complete.Predictor {
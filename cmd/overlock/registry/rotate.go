@@ -0,0 +1,53 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type rotateCmd struct {
+	Name             string `arg:"" required:"" help:"Registry name."`
+	FromFile         string `help:"Read new password or token from file." xor:"source" type:"existingfile"`
+	FromEnv          string `help:"Read new password or token from environment variable." xor:"source"`
+	FromDockerConfig bool   `help:"Read new credentials from Docker config file and credential helpers." xor:"source"`
+	Username         string `help:"New Username, keeps current when empty."`
+}
+
+func (c *rotateCmd) Run(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, logger *zap.SugaredLogger) error {
+	reg, err := registry.Get(ctx, client, c.Name)
+	if err != nil {
+		return err
+	}
+
+	creds := registry.Credentials{Username: c.Username}
+	switch {
+	case c.FromFile != "":
+		creds.Password, err = registry.PasswordFromFile(c.FromFile)
+	case c.FromEnv != "":
+		creds.Password, err = registry.PasswordFromEnv(c.FromEnv)
+	case c.FromDockerConfig:
+		var docker *registry.Credentials
+		docker, err = registry.CredentialsFromDockerConfig(reg.Server)
+		if err == nil {
+			// Explicitly set username takes precedence over Docker config
+			creds, err = creds.Merge(*docker)
+		}
+	default:
+		return fmt.Errorf("credentials source is required, use --from-file, --from-env or --from-docker-config")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = reg.UpdateCredentials(ctx, config, creds, logger)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Registry %s credentials rotated successfully.", c.Name)
+	return nil
+}
@@ -13,8 +13,9 @@ import (
 // SearchCmd is the struct representing the search command
 type SearchCmd struct {
 	// Query is the search query
-	Query    string `arg:"" help:"search query"`
-	Versions bool   `optional:""  short:"v" help:"display all versions"`
+	Query string `arg:"" help:"search query"`
+	// Versions has no short flag, -v is global --engine-version
+	Versions bool `optional:"" help:"display all versions"`
 }
 
 func (c *SearchCmd) Run(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, logger *zap.SugaredLogger) error {
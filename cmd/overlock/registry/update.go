@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+type updateCmd struct {
+	Name             string `arg:"" required:"" help:"Registry name."`
+	RegistryServer   string `help:"New Private Registry FQDN, keeps current when empty."`
+	Username         string `help:"New Username, keeps current when empty."`
+	Password         string `help:"New Password."`
+	PasswordStdin    bool   `help:"Read new password from STDIN."`
+	Email            string `help:"New Email, keeps current when empty."`
+	FromDockerConfig bool   `help:"Take credentials from Docker config file and credential helpers."`
+}
+
+func (c *updateCmd) Run(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, logger *zap.SugaredLogger) error {
+	reg, err := registry.Get(ctx, client, c.Name)
+	if err != nil {
+		return err
+	}
+
+	creds := registry.Credentials{
+		Server:   c.RegistryServer,
+		Username: c.Username,
+		Password: c.Password,
+		Email:    c.Email,
+	}
+	if c.PasswordStdin {
+		creds.Password, err = registry.PasswordFromReader(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read password from STDIN: %w", err)
+		}
+	}
+	if c.FromDockerConfig {
+		server := c.RegistryServer
+		if server == "" {
+			server = reg.Server
+		}
+		docker, err := registry.CredentialsFromDockerConfig(server)
+		if err != nil {
+			return err
+		}
+		// Explicitly set flags take precedence over Docker config
+		creds, err = creds.Merge(*docker)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = reg.UpdateCredentials(ctx, config, creds, logger)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Registry %s updated successfully.", c.Name)
+	return nil
+}
@@ -0,0 +1,103 @@
+package runtimeconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alecthomas/kong"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/runtimeconfig"
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+// Commands of DeploymentRuntimeConfig of package kind, set with kind variable
+// of parent command, like set:"kind=provider"
+type Cmd struct {
+	Create createCmd `cmd:"" help:"Create DeploymentRuntimeConfig and attach it to ${kind}."`
+	Set    setCmd    `cmd:"" help:"Change DeploymentRuntimeConfig attached to ${kind}."`
+	Attach attachCmd `cmd:"" help:"Attach existing DeploymentRuntimeConfig to ${kind}."`
+}
+
+type flags struct {
+	Debug    bool     `optional:"" name:"runtime-debug" help:"Run ${kind} with --debug argument, global --debug enables debug of overlock."`
+	Env      []string `optional:"" help:"Environment variables of ${kind}, like KEY=VALUE."`
+	CPU      string   `optional:"" name:"cpu" help:"CPU limit of ${kind}, like 500m."`
+	Memory   string   `optional:"" help:"Memory limit of ${kind}, like 512Mi."`
+	Replicas int      `optional:"" help:"Replicas of ${kind} deployment."`
+}
+
+func (f flags) options() runtimeconfig.Options {
+	return runtimeconfig.Options{
+		Debug:    f.Debug,
+		Env:      f.Env,
+		CPU:      f.CPU,
+		Memory:   f.Memory,
+		Replicas: f.Replicas,
+	}
+}
+
+// Package kind of selected command
+func packageKind(kctx *kong.Context) (xpkg.Package, error) {
+	return xpkg.New(kctx.Selected().Vars()["kind"])
+}
+
+type createCmd struct {
+	Package string `arg:"" help:"Name or reference of installed ${kind}."`
+	Name    string `optional:"" help:"Name of runtime config, defaults to name of ${kind}."`
+
+	flags `embed:""`
+}
+
+func (c *createCmd) Run(ctx context.Context, kctx *kong.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := packageKind(kctx)
+	if err != nil {
+		return err
+	}
+	name := c.Name
+	if name == "" {
+		name, err = pkg.Name(ctx, dc, c.Package)
+		if err != nil {
+			return err
+		}
+	}
+	if err := runtimeconfig.Create(ctx, dc, name, c.options(), logger); err != nil {
+		return err
+	}
+	return runtimeconfig.Attach(ctx, dc, pkg, c.Package, name, logger)
+}
+
+type setCmd struct {
+	Package string `arg:"" help:"Name or reference of installed ${kind}."`
+
+	flags `embed:""`
+}
+
+func (c *setCmd) Run(ctx context.Context, kctx *kong.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := packageKind(kctx)
+	if err != nil {
+		return err
+	}
+	name, err := pkg.RuntimeConfig(ctx, dc, c.Package)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("%s %s has no runtime config, create it with runtime-config create", pkg.Kind(), c.Package)
+	}
+	return runtimeconfig.Set(ctx, dc, name, c.options(), logger)
+}
+
+type attachCmd struct {
+	Package       string `arg:"" help:"Name or reference of installed ${kind}."`
+	RuntimeConfig string `arg:"" help:"Name of DeploymentRuntimeConfig."`
+}
+
+func (c *attachCmd) Run(ctx context.Context, kctx *kong.Context, dc *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
+	pkg, err := packageKind(kctx)
+	if err != nil {
+		return err
+	}
+	return runtimeconfig.Attach(ctx, dc, pkg, c.Package, c.RuntimeConfig, logger)
+}
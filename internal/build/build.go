@@ -0,0 +1,132 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// Project file of package directory with build section
+const ProjectFile = "overlock-build.yaml"
+
+// Build of binary run by provider or function package
+type Config struct {
+	// Target platforms, like linux/arm64, multi-platform package is built
+	// when more than one is set, host platform is used when empty
+	Platforms []string `json:"platforms,omitempty"`
+	LDFlags   string   `json:"ldflags,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	// Additional environment of build, like GOPRIVATE=example.com
+	Env []string `json:"env,omitempty"`
+	// Command used instead of go build, like for non-Go functions. It is run
+	// in main module directory and writes binary to path of OUTPUT variable.
+	Command string `json:"command,omitempty"`
+}
+
+type project struct {
+	Build Config `json:"build"`
+}
+
+// Load build section of project file in package directory, empty config is
+// returned when file does not exist
+func LoadConfig(path string) (*Config, error) {
+	file := filepath.Join(path, ProjectFile)
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := &project{}
+	if err := yaml.UnmarshalStrict(content, p); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s", file)
+	}
+	return &p.Build, nil
+}
+
+// Override config with values set in other config, like flags
+func (c *Config) Merge(other Config) {
+	if len(other.Platforms) > 0 {
+		c.Platforms = other.Platforms
+	}
+	if other.LDFlags != "" {
+		c.LDFlags = other.LDFlags
+	}
+	if len(other.Tags) > 0 {
+		c.Tags = other.Tags
+	}
+	c.Env = append(c.Env, other.Env...)
+	if other.Command != "" {
+		c.Command = other.Command
+	}
+}
+
+// Parsed target platforms, nil platform stands for host platform
+func (c *Config) TargetPlatforms() ([]*regv1.Platform, error) {
+	if len(c.Platforms) == 0 {
+		return []*regv1.Platform{nil}, nil
+	}
+	platforms := []*regv1.Platform{}
+	for _, p := range c.Platforms {
+		platform, err := regv1.ParsePlatform(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid platform %s", p)
+		}
+		if platform.OS == "" || platform.Architecture == "" {
+			return nil, fmt.Errorf("invalid platform %s, expected os/arch", p)
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+// Build binary of main module for platform and return its content
+func (c *Config) Binary(ctx context.Context, mainPath string, binary string, platform *regv1.Platform) ([]byte, error) {
+	output, err := filepath.Abs(filepath.Join(mainPath, binary))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(output)
+
+	var cmd *exec.Cmd
+	if c.Command != "" {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.Command)
+		cmd.Dir = mainPath
+	} else {
+		args := []string{"build", "-C", mainPath, "-o", output}
+		if len(c.Tags) > 0 {
+			args = append(args, "-tags", strings.Join(c.Tags, ","))
+		}
+		if c.LDFlags != "" {
+			args = append(args, "-ldflags", c.LDFlags)
+		}
+		cmd = exec.CommandContext(ctx, "go", args...)
+	}
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "OUTPUT="+output)
+	if platform != nil {
+		cmd.Env = append(cmd.Env, "GOOS="+platform.OS, "GOARCH="+platform.Architecture)
+		if platform.Variant != "" && platform.Architecture == "arm" {
+			cmd.Env = append(cmd.Env, "GOARM="+strings.TrimPrefix(platform.Variant, "v"))
+		}
+	}
+	cmd.Env = append(cmd.Env, c.Env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to build %s", binary)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		return nil, errors.Wrapf(err, "binary %s not found after build", output)
+	}
+	return content, nil
+}
@@ -5,7 +5,9 @@ import (
 	"net/url"
 
 	"github.com/web-seven/overlock/internal/install/helm"
+	"github.com/web-seven/overlock/internal/kube"
 	"github.com/web-seven/overlock/internal/namespace"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,6 +21,7 @@ const (
 	certManagerReleaseName  = "cert-manager"
 	certManagerRepoUrl      = "https://charts.jetstack.io"
 	certManagerNamespace    = "cert-manager"
+	certManagerCRDGroup     = "cert-manager.io"
 
 	clusterIssuerName  = "overlock-selfsigned"
 	registryCertName   = "registry-tls"
@@ -160,6 +163,81 @@ func CreateRegistryCertificate(ctx context.Context, config *rest.Config) error {
 	return nil
 }
 
+// UninstallCertManager removes overlock issuer and certificate, the cert-manager
+// release, its CRDs and namespace
+func UninstallCertManager(ctx context.Context, config *rest.Config) error {
+	if err := DeleteRegistryCertificate(ctx, config); err != nil {
+		return err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	issuerGVR := schema.GroupVersionResource{
+		Group:    "cert-manager.io",
+		Version:  "v1",
+		Resource: "clusterissuers",
+	}
+	err = dynamicClient.Resource(issuerGVR).Delete(ctx, clusterIssuerName, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	repoURL, err := url.Parse(certManagerRepoUrl)
+	if err != nil {
+		return err
+	}
+	manager, err := helm.NewManager(config, certManagerChartName, repoURL, certManagerReleaseName,
+		helm.InstallerModifierFn(helm.Wait()),
+		helm.InstallerModifierFn(helm.WithNamespace(certManagerNamespace)),
+	)
+	if err != nil {
+		return err
+	}
+	release, _ := manager.GetRelease()
+	if release != nil {
+		if err := manager.Uninstall(); err != nil {
+			return err
+		}
+	}
+
+	err = kube.DeleteCustomResourceDefinitions(ctx, config, certManagerCRDGroup)
+	if err != nil {
+		return err
+	}
+
+	client, err := kube.Client(config)
+	if err != nil {
+		return err
+	}
+	err = client.CoreV1().Namespaces().Delete(ctx, certManagerNamespace, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteRegistryCertificate deletes the Certificate of the registry service
+func DeleteRegistryCertificate(ctx context.Context, config *rest.Config) error {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    "cert-manager.io",
+		Version:  "v1",
+		Resource: "certificates",
+	}
+
+	err = dynamicClient.Resource(gvr).Namespace(namespace.Namespace).Delete(ctx, registryCertName, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 // GetRegistrySecretName returns the name of the TLS secret for the registry
 func GetRegistrySecretName() string {
 	return registrySecretName
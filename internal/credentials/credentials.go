@@ -0,0 +1,202 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/namespace"
+)
+
+// Formats of credential files
+const (
+	FormatAWS        = "aws"
+	FormatGCP        = "gcp"
+	FormatAzure      = "azure"
+	FormatKubeconfig = "kubeconfig"
+	FormatGeneric    = "generic"
+)
+
+const (
+	secretKey              = "credentials"
+	providerConfigResource = "providerconfigs"
+)
+
+// ProviderConfig of provider package family with format of its credentials
+type Template struct {
+	// Repository paths of family, matching members like provider-aws-s3
+	Families   []string
+	Format     string
+	APIVersion string
+}
+
+// Templates of known provider families, first match wins
+var Templates = []Template{
+	{Families: []string{"crossplane-contrib/provider-aws"}, Format: FormatAWS, APIVersion: "aws.crossplane.io/v1beta1"},
+	{Families: []string{"provider-aws", "provider-family-aws"}, Format: FormatAWS, APIVersion: "aws.upbound.io/v1beta1"},
+	{Families: []string{"provider-gcp", "provider-family-gcp"}, Format: FormatGCP, APIVersion: "gcp.upbound.io/v1beta1"},
+	{Families: []string{"provider-azure", "provider-family-azure"}, Format: FormatAzure, APIVersion: "azure.upbound.io/v1beta1"},
+	{Families: []string{"provider-kubernetes"}, Format: FormatKubeconfig, APIVersion: "kubernetes.crossplane.io/v1alpha1"},
+	{Families: []string{"provider-helm"}, Format: FormatKubeconfig, APIVersion: "helm.crossplane.io/v1beta1"},
+}
+
+// Options of credentials bootstrap
+type Options struct {
+	// Credentials file, like ~/.aws/credentials
+	File string
+	// Profile of AWS credentials or context of kubeconfig
+	Profile string
+	// Format of file, detected from provider family if empty
+	Format string
+	// API version of ProviderConfig, detected from provider family if empty
+	APIVersion string
+	// Name of ProviderConfig
+	ConfigName string
+	// Name of Secret, named by provider if empty
+	SecretName string
+	// GCP project, taken from service account key if empty
+	ProjectID string
+}
+
+// Template of provider package, like xpkg.upbound.io/upbound/provider-aws-s3:v1
+func TemplateOf(pkg string) (*Template, error) {
+	ref, err := name.ParseReference(pkg, name.WithDefaultRegistry(""))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid provider package %s", pkg)
+	}
+	path := ref.Context().RepositoryStr()
+	base := path[strings.LastIndex(path, "/")+1:]
+	for i, t := range Templates {
+		for _, family := range t.Families {
+			if inFamily(path, family) || inFamily(base, family) {
+				return &Templates[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Repository is family itself or its member, like provider-aws-s3
+func inFamily(repository string, family string) bool {
+	return repository == family || strings.HasPrefix(repository, family+"-")
+}
+
+// Create Secret with credentials from file and ProviderConfig referencing it
+func Bootstrap(ctx context.Context, client kubernetes.Interface, dc dynamic.Interface, providerName string, pkg string, opts Options, logger *zap.SugaredLogger) error {
+	format, apiVersion := opts.Format, opts.APIVersion
+	tmpl, err := TemplateOf(pkg)
+	if err != nil {
+		return err
+	}
+	if tmpl != nil {
+		if format == "" {
+			format = tmpl.Format
+		}
+		if apiVersion == "" {
+			apiVersion = tmpl.APIVersion
+		}
+	}
+	if format == "" {
+		format = FormatGeneric
+	}
+	if apiVersion == "" {
+		return fmt.Errorf("provider %s has no ProviderConfig template, set its API version", pkg)
+	}
+
+	content, err := os.ReadFile(opts.File)
+	if err != nil {
+		return err
+	}
+	creds, err := convert(format, content, opts.Profile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s credentials from %s", format, opts.File)
+	}
+
+	secretName := opts.SecretName
+	if secretName == "" {
+		secretName = providerName + "-credentials"
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace.Namespace,
+		},
+		Data: map[string][]byte{secretKey: creds.content},
+	}
+	if err := kube.NewSecretApplicator(client).Apply(ctx, namespace.Namespace, secret); err != nil {
+		return errors.Wrapf(err, "failed to apply secret %s", secretName)
+	}
+	logger.Infof("Secret %s/%s applied.", namespace.Namespace, secretName)
+
+	spec := map[string]interface{}{
+		"credentials": map[string]interface{}{
+			"source": "Secret",
+			"secretRef": map[string]interface{}{
+				"namespace": namespace.Namespace,
+				"name":      secretName,
+				"key":       secretKey,
+			},
+		},
+	}
+	if format == FormatGCP {
+		projectID := opts.ProjectID
+		if projectID == "" {
+			projectID = creds.projectID
+		}
+		if projectID == "" {
+			return fmt.Errorf("GCP project ID not found in %s, set it explicitly", opts.File)
+		}
+		spec["projectID"] = projectID
+	}
+	return applyProviderConfig(ctx, dc, apiVersion, opts.ConfigName, spec, logger)
+}
+
+// Create or replace spec of ProviderConfig
+func applyProviderConfig(ctx context.Context, dc dynamic.Interface, apiVersion string, configName string, spec map[string]interface{}, logger *zap.SugaredLogger) error {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return err
+	}
+	resource := dc.Resource(gv.WithResource(providerConfigResource))
+
+	existing, err := resource.Get(ctx, configName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       "ProviderConfig",
+				"metadata": map[string]interface{}{
+					"name": configName,
+				},
+				"spec": spec,
+			},
+		}
+		if _, err := resource.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to create ProviderConfig %s", configName)
+		}
+		logger.Infof("ProviderConfig %s created.", configName)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get ProviderConfig %s, is provider healthy", configName)
+	}
+	existing.Object["spec"] = spec
+	if _, err := resource.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update ProviderConfig %s", configName)
+	}
+	logger.Infof("ProviderConfig %s updated.", configName)
+	return nil
+}
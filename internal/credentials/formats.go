@@ -0,0 +1,156 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const defaultProfile = "default"
+
+// Content of credentials Secret with values provider config may need
+type credentials struct {
+	content   []byte
+	projectID string
+}
+
+// Convert credentials file to content expected by provider
+func convert(format string, content []byte, profile string) (*credentials, error) {
+	switch format {
+	case FormatAWS:
+		return convertAWS(content, profile)
+	case FormatGCP:
+		return convertGCP(content)
+	case FormatAzure:
+		if !json.Valid(content) {
+			return nil, fmt.Errorf("azure credentials must be JSON, like output of az ad sp create-for-rbac --sdk-auth")
+		}
+		return &credentials{content: content}, nil
+	case FormatKubeconfig:
+		return convertKubeconfig(content, profile)
+	case FormatGeneric:
+		return convertGeneric(content)
+	}
+	return nil, fmt.Errorf("unknown credentials format %s", format)
+}
+
+// Profile of AWS shared credentials file as default profile
+func convertAWS(content []byte, profile string) (*credentials, error) {
+	if profile == "" {
+		profile = defaultProfile
+	}
+	values := map[string]string{}
+	section := ""
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(line, "]"), "["))
+			found = found || section == profile
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("profile %s not found", profile)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("[default]\n")
+	for _, key := range []string{"aws_access_key_id", "aws_secret_access_key", "aws_session_token"} {
+		value, ok := values[key]
+		if !ok {
+			if key == "aws_session_token" {
+				continue
+			}
+			return nil, fmt.Errorf("profile %s has no %s", profile, key)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", key, value)
+	}
+	return &credentials{content: buf.Bytes()}, nil
+}
+
+// Service account key, project of key is used by provider config
+func convertGCP(content []byte) (*credentials, error) {
+	key := struct {
+		Type      string `json:"type"`
+		ProjectID string `json:"project_id"`
+	}{}
+	if err := json.Unmarshal(content, &key); err != nil {
+		return nil, errors.Wrap(err, "gcp credentials must be service account key JSON")
+	}
+	return &credentials{content: content, projectID: key.ProjectID}, nil
+}
+
+// Kubeconfig reduced to single context with embedded certificates
+func convertKubeconfig(content []byte, context string) (*credentials, error) {
+	cfg, err := clientcmd.Load(content)
+	if err != nil {
+		return nil, err
+	}
+	if context != "" {
+		if _, ok := cfg.Contexts[context]; !ok {
+			return nil, fmt.Errorf("context %s not found", context)
+		}
+		cfg.CurrentContext = context
+	}
+	if err := api.MinifyConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := api.FlattenConfig(cfg); err != nil {
+		return nil, err
+	}
+	out, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &credentials{content: out}, nil
+}
+
+// Lines of KEY=VALUE as JSON object
+func convertGeneric(content []byte) (*credentials, error) {
+	if json.Valid(content) {
+		return &credentials{content: content}, nil
+	}
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid line %q, expected KEY=VALUE", line)
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &credentials{content: out}, nil
+}
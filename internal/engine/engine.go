@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/web-seven/overlock/internal/install"
 	"github.com/web-seven/overlock/internal/install/helm"
+	"github.com/web-seven/overlock/internal/kube"
 	"github.com/web-seven/overlock/internal/namespace"
 	"k8s.io/client-go/rest"
 
@@ -45,6 +46,9 @@ var (
 		},
 		"args": []string{},
 	}
+	crdGroups = []string{
+		"crossplane.io",
+	}
 	apis = []string{
 		"configurations.pkg.crossplane.io",
 
@@ -99,6 +103,26 @@ func InstallEngine(ctx context.Context, configClient *rest.Config, params map[st
 	return engine.Install(Version, params)
 }
 
+// Uninstall engine Helm release and remove Crossplane CRDs left by the chart
+func UninstallEngine(ctx context.Context, configClient *rest.Config, logger *zap.SugaredLogger) error {
+	engine, err := GetEngine(configClient)
+	if err != nil {
+		return err
+	}
+
+	if _, err := engine.GetRelease(); err != nil {
+		logger.Debugf("Engine release not found: %v", err)
+	} else {
+		logger.Debug("Uninstall Crossplane engine")
+		if err := engine.Uninstall(); err != nil {
+			return errors.Wrap(err, "failed to uninstall engine release")
+		}
+	}
+
+	logger.Debug("Delete Crossplane CRDs")
+	return kube.DeleteCustomResourceDefinitions(ctx, configClient, crdGroups...)
+}
+
 // Verify if Crossplane API exists
 func VerifyApi(ctx context.Context, configClient *rest.Config, apiName string) (bool, error) {
 	crdClientSet, err := clientset.NewForConfig(configClient)
@@ -4,11 +4,8 @@ import (
 	"context"
 
 	condition "github.com/crossplane/crossplane-runtime/apis/common/v1"
-	"github.com/google/go-containerregistry/pkg/v1/empty"
 
-	"github.com/web-seven/overlock/internal/image"
 	"github.com/web-seven/overlock/internal/kube"
-	"github.com/web-seven/overlock/internal/packages"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,19 +18,6 @@ const (
 	apiPlural  = "functions"
 )
 
-type Function struct {
-	Name  string
-	Image image.Image
-	packages.Package
-}
-
-func New(name string) *Function {
-	return &Function{
-		Name:  name,
-		Image: image.Image{Image: empty.Image},
-	}
-}
-
 func CheckHealthStatus(status []condition.Condition) bool {
 	healthStatus := false
 	for _, condition := range status {
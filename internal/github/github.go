@@ -2,18 +2,49 @@ package github
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"net/http"
 	"strings"
 
-	"github.com/pterm/pterm"
-	"github.com/web-seven/overlock/pkg/registry"
 	"go.uber.org/zap"
 
 	"github.com/google/go-github/v61/github"
 )
 
-func getAllPackages(ctx context.Context, client *github.Client, org string, opts *github.PackageListOptions, allPkgs []*github.Package) ([]*github.Package, error) {
-	pkgs, resp, err := client.Organizations.ListPackages(ctx, org, opts)
+const pkgType = "container"
+
+// Container package of GitHub organization or user with its tags
+type Package struct {
+	Name     string
+	Versions []string
+}
+
+// Packages owner, which could be an organization or a user
+type owner struct {
+	client *github.Client
+	name   string
+	isUser bool
+}
+
+func (o *owner) listPackages(ctx context.Context, opts *github.PackageListOptions) ([]*github.Package, *github.Response, error) {
+	if o.isUser {
+		return o.client.Users.ListPackages(ctx, o.name, opts)
+	}
+	return o.client.Organizations.ListPackages(ctx, o.name, opts)
+}
+
+func (o *owner) packageVersions(ctx context.Context, pkgName string) ([]*github.PackageVersion, error) {
+	opts := &github.PackageListOptions{}
+	if o.isUser {
+		versions, _, err := o.client.Users.PackageGetAllVersions(ctx, o.name, pkgType, pkgName, opts)
+		return versions, err
+	}
+	versions, _, err := o.client.Organizations.PackageGetAllVersions(ctx, o.name, pkgType, pkgName, opts)
+	return versions, err
+}
+
+func getAllPackages(ctx context.Context, o *owner, opts *github.PackageListOptions, allPkgs []*github.Package) ([]*github.Package, error) {
+	pkgs, resp, err := o.listPackages(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -24,60 +55,57 @@ func getAllPackages(ctx context.Context, client *github.Client, org string, opts
 	}
 
 	opts.Page = resp.NextPage
-	return getAllPackages(ctx, client, org, opts, allPkgs)
+	return getAllPackages(ctx, o, opts, allPkgs)
 }
 
-// GetPackages list packages and their versions from Container Registry
-func GetPackages(ctx context.Context, query string, version bool, r *registry.Registry, registryUrl string, org string, logger *zap.SugaredLogger) (pterm.TableData, error) {
-	auth := registry.RegistryConfig{}
-	json.Unmarshal([]byte(r.Data[".dockerconfigjson"]), &auth)
-	clientgh := github.NewClient(nil).WithAuthToken(auth.Auths[registryUrl].Password)
-	tableRegs := pterm.TableData{
-		{"URL", "VERSION"},
+// GetPackages list packages of organization or user, matching query, and their
+// tags from Container Registry. Only latest version is returned unless requested.
+func GetPackages(ctx context.Context, query string, version bool, token string, ownerName string, logger *zap.SugaredLogger) ([]Package, error) {
+	client := github.NewClient(nil)
+	if token != "" {
+		client = client.WithAuthToken(token)
 	}
-	pkgType := "container"
-	var allPkgs []*github.Package
+	o := &owner{client: client, name: ownerName}
+	packageType := pkgType
 	opts := &github.PackageListOptions{
-		PackageType: &pkgType,
+		PackageType: &packageType,
 	}
 
-	allPkgs, err := getAllPackages(ctx, clientgh, org, opts, allPkgs)
+	allPkgs, err := getAllPackages(ctx, o, opts, nil)
+	var ghErr *github.ErrorResponse
+	if err != nil && errors.As(err, &ghErr) && ghErr.Response.StatusCode == http.StatusNotFound {
+		logger.Debugf("Organization %s not found, looking for user packages", ownerName)
+		o.isUser = true
+		opts.Page = 0
+		allPkgs, err = getAllPackages(ctx, o, opts, nil)
+	}
 	if err != nil {
-		logger.Errorf("Cannot get packages from %s", registryUrl)
+		logger.Errorf("Cannot get packages of %s", ownerName)
 		return nil, err
 	}
 
-	pkgVersions := make(map[string][]*github.PackageVersion)
+	result := []Package{}
 	for _, pkg := range allPkgs {
-		versions, _, err := clientgh.Organizations.PackageGetAllVersions(ctx, org, pkgType, pkg.GetName(), nil)
+		if !strings.Contains(pkg.GetName(), query) {
+			continue
+		}
+		versions, err := o.packageVersions(ctx, pkg.GetName())
 		if err != nil {
-			logger.Errorf("Cannot get package versions for %s/%s", org, *pkg.Name)
+			logger.Errorf("Cannot get package versions for %s/%s", ownerName, pkg.GetName())
 			return nil, err
 		}
-		if !version {
-			if len(versions) > 0 {
-				pkgVersions[*pkg.Name] = []*github.PackageVersion{versions[0]}
-			}
-		} else {
-			pkgVersions[*pkg.Name] = versions
-		}
-	}
-
-	for _, pkg := range allPkgs {
-		if !strings.Contains(*pkg.Name, query) {
-			continue
+		if !version && len(versions) > 1 {
+			versions = versions[:1]
 		}
-		versions := pkgVersions[*pkg.Name]
+		found := Package{Name: pkg.GetName()}
 		for _, v := range versions {
-			tags := v.GetMetadata().Container.Tags
+			tags := v.GetMetadata().GetContainer().Tags
 			if len(tags) > 0 {
-				tableRegs = append(tableRegs, []string{
-					"ghcr.io/" + org + "/" + *pkg.Name,
-					tags[0],
-				})
+				found.Versions = append(found.Versions, tags[0])
 			}
 		}
+		result = append(result, found)
 	}
 
-	return tableRegs, nil
+	return result, nil
 }
@@ -4,7 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
-	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
@@ -13,6 +13,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/web-seven/overlock/internal/loader"
 	yaml "gopkg.in/yaml.v2"
@@ -82,11 +83,11 @@ func LoadPackageLayerDirectory(ctx context.Context, config *rest.Config, path st
 			res := &metav1.TypeMeta{}
 			yamlFile, err := os.ReadFile(path)
 			if err != nil {
-				return errors.New("can't read")
+				return fmt.Errorf("can't read %s: %w", path, err)
 			}
 			err = yaml.Unmarshal(yamlFile, res)
 			if err != nil {
-				return errors.New("can't unmarshal")
+				return fmt.Errorf("can't unmarshal %s: %w", path, err)
 			}
 
 			if slices.Contains(kindsFilter, res.Kind) {
@@ -117,3 +118,29 @@ func LoadPackageLayerDirectory(ctx context.Context, config *rest.Config, path st
 	}
 	return layer, nil
 }
+
+// Append layer annotated as xpkg layer, like base, with the same label in
+// image configuration as Crossplane package builder sets
+func AppendAnnotatedLayer(img v1.Image, layer v1.Layer, annotation string) (v1.Image, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	if cfg.Config.Labels == nil {
+		cfg.Config.Labels = map[string]string{}
+	}
+	cfg.Config.Labels[AnnotationKey+":"+digest.String()] = annotation
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.Append(img, mutate.Addendum{
+		Layer:       layer,
+		Annotations: map[string]string{AnnotationKey: annotation},
+	})
+}
@@ -470,7 +470,10 @@ func (h *Installer) Upgrade(version string, parameters map[string]any, opts ...i
 
 // Uninstall uninstalls an installation.
 func (h *Installer) Uninstall() error {
-	_, err := h.uninstallClient.Run(h.chartName)
+	if _, err := h.GetCurrentVersion(); err != nil {
+		return err
+	}
+	_, err := h.uninstallClient.Run(h.releaseName)
 	return err
 }
 
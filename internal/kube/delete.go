@@ -2,11 +2,19 @@ package kube
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
 )
 
+const deletionPollInterval = 2 * time.Second
+
 func DeleteKubeResources(ctx context.Context, p ResourceParams, resourceName string) error {
 	resourceID := schema.GroupVersionResource{
 		Group:    p.Group,
@@ -21,3 +29,52 @@ func DeleteKubeResources(ctx context.Context, p ResourceParams, resourceName str
 
 	return err
 }
+
+// WaitKubeResourcesDeleted polls resources described by params until none of
+// them is left or timeout is reached. Missing resource types count as deleted.
+func WaitKubeResourcesDeleted(ctx context.Context, p ResourceParams, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(ctx, deletionPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		p.Ctx = ctx
+		items, err := GetKubeResources(p)
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return len(items) == 0, nil
+	})
+}
+
+// DeleteCustomResourceDefinitions deletes all CRDs which API group is equal to
+// or ends with one of the provided group suffixes.
+func DeleteCustomResourceDefinitions(ctx context.Context, config *rest.Config, groupSuffixes ...string) error {
+	crdClientSet, err := clientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	crds := crdClientSet.ApiextensionsV1().CustomResourceDefinitions()
+	list, err := crds.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, crd := range list.Items {
+		if !matchesGroup(crd.Spec.Group, groupSuffixes) {
+			continue
+		}
+		err := crds.Delete(ctx, crd.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesGroup(group string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if group == suffix || strings.HasSuffix(group, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package loader
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	ociLayoutFile      = "oci-layout"
+	ociIndexFile       = "index.json"
+	dockerManifestFile = "manifest.json"
+)
+
+// Load image index from OCI layout directory, OCI layout archive or Docker
+// archive, keeping all platforms. Index is nil when path is not an image
+// archive. Cleanup removes temporary files and must be called after index is
+// pushed.
+func LoadIndex(path string) (v1.ImageIndex, func(), error) {
+	cleanup := func() {}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	if fi.IsDir() {
+		if !fileExists(filepath.Join(path, ociIndexFile)) {
+			return nil, cleanup, fmt.Errorf("directory %s is not an OCI layout", path)
+		}
+		index, err := layout.ImageIndexFromPath(path)
+		return index, cleanup, err
+	}
+
+	files, err := archiveFiles(path)
+	if err != nil {
+		// Not a TAR archive, like compressed Helm chart
+		return nil, cleanup, nil
+	}
+	switch {
+	case files[ociLayoutFile] || files[ociIndexFile]:
+		dir, err := os.MkdirTemp("", "overlock-layout-")
+		if err != nil {
+			return nil, cleanup, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+		if err := extractArchive(path, dir); err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		index, err := layout.ImageIndexFromPath(dir)
+		if err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		return index, cleanup, nil
+	case files[dockerManifestFile]:
+		index, err := dockerArchiveIndex(path)
+		return index, cleanup, err
+	}
+	return nil, cleanup, nil
+}
+
+// Index of images from Docker archive, with platform of every image
+func dockerArchiveIndex(path string) (v1.ImageIndex, error) {
+	opener := func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	manifest, err := tarball.LoadManifest(opener)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest) > 1 {
+		for _, desc := range manifest {
+			if len(desc.RepoTags) == 0 || desc.RepoTags[0] == "" {
+				return nil, fmt.Errorf("docker archive %s has untagged images, save them with tags or as OCI layout", path)
+			}
+		}
+	}
+
+	index := mutate.IndexMediaType(empty.Index, types.DockerManifestList)
+	for _, desc := range manifest {
+		var tag *name.Tag
+		if len(manifest) > 1 {
+			t, err := name.NewTag(desc.RepoTags[0])
+			if err != nil {
+				return nil, err
+			}
+			tag = &t
+		}
+		img, err := tarball.Image(opener, tag)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := img.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: v1.Descriptor{
+				Platform: cfg.Platform(),
+			},
+		})
+	}
+	return index, nil
+}
+
+// Names of top level files of TAR archive
+func archiveFiles(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	files := map[string]bool{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[strings.TrimPrefix(hdr.Name, "./")] = true
+	}
+	return files, nil
+}
+
+// Extract TAR archive to directory
+func extractArchive(path string, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.Clean("/"+hdr.Name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.Create(target)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
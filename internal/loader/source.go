@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	docker "github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+// Image source schemes
+const (
+	OCILayoutScheme     = "oci-layout://"
+	DockerArchiveScheme = "docker-archive://"
+	DockerDaemonScheme  = "docker-daemon://"
+)
+
+// Load image index from source, which could be OCI layout, Docker archive,
+// image of local Docker daemon or path to archive detected by its content.
+// Index is nil when path without scheme is not an image archive.
+func LoadSource(ctx context.Context, source string) (v1.ImageIndex, func(), error) {
+	switch {
+	case strings.HasPrefix(source, OCILayoutScheme):
+		path := strings.TrimPrefix(source, OCILayoutScheme)
+		index, cleanup, err := LoadIndex(path)
+		if err == nil && index == nil {
+			err = fmt.Errorf("%s is not an OCI layout", path)
+		}
+		return index, cleanup, err
+	case strings.HasPrefix(source, DockerArchiveScheme):
+		index, err := dockerArchiveIndex(strings.TrimPrefix(source, DockerArchiveScheme))
+		return index, func() {}, err
+	case strings.HasPrefix(source, DockerDaemonScheme):
+		index, err := daemonIndex(ctx, strings.TrimPrefix(source, DockerDaemonScheme))
+		return index, func() {}, err
+	}
+	return LoadIndex(source)
+}
+
+// Index with image of local Docker daemon
+func daemonIndex(ctx context.Context, imageName string) (v1.ImageIndex, error) {
+	ref, err := name.ParseReference(imageName)
+	if err != nil {
+		return nil, err
+	}
+	dockerClient, err := docker.NewClientWithOpts(docker.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	img, err := daemon.Image(ref, daemon.WithContext(ctx), daemon.WithClient(dockerClient))
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add: img,
+		Descriptor: v1.Descriptor{
+			Platform: cfg.Platform(),
+		},
+	}), nil
+}
@@ -0,0 +1,192 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+
+	"github.com/web-seven/overlock/internal/packages"
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+const DefaultFile = "overlock.lock"
+
+// Packages pinned to digests, shared to get identical environments
+type Lock struct {
+	Packages []Package `json:"packages"`
+}
+
+// Package reference resolved to digest together with its dependencies
+type Package struct {
+	Kind         string       `json:"kind"`
+	Package      string       `json:"package"`
+	Digest       string       `json:"digest"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+}
+
+// Dependency of locked package resolved to digest
+type Dependency struct {
+	Kind       string `json:"kind"`
+	Package    string `json:"package"`
+	Constraint string `json:"constraint,omitempty"`
+	Digest     string `json:"digest"`
+}
+
+// Load lock file, empty lock is returned when file does not exist
+func Load(path string) (*Lock, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Lock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l := &Lock{}
+	if err := yaml.Unmarshal(content, l); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse lock file %s", path)
+	}
+	return l, nil
+}
+
+// Write lock file
+func (l *Lock) Save(path string) error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// Resolve package reference with dependency tree to digests
+func Resolve(ctx context.Context, ref string, opts ...crane.Option) (*Package, error) {
+	tree, err := packages.ResolveTree(ref, append([]crane.Option{crane.WithContext(ctx)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	// Digests of indexes are pinned for multi-platform packages, so every
+	// platform resolves the same package
+	pkg := &Package{
+		Kind:    tree.Kind,
+		Package: ref,
+		Digest:  tree.Digest,
+	}
+	err = tree.Walk(func(n *packages.Node) error {
+		if n == tree {
+			return nil
+		}
+		pkg.Dependencies = append(pkg.Dependencies, Dependency{
+			Kind:       n.Kind,
+			Package:    n.Repository,
+			Constraint: n.Constraint,
+			Digest:     n.Digest,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Add package or replace locked package of the same repository
+func (l *Lock) Set(pkg Package) {
+	repo := pkg.Repository()
+	for i := range l.Packages {
+		if l.Packages[i].Repository() == repo {
+			l.Packages[i] = pkg
+			return
+		}
+	}
+	l.Packages = append(l.Packages, pkg)
+}
+
+// Resolve all locked packages again from their references
+func (l *Lock) Update(ctx context.Context, logger *zap.SugaredLogger, opts ...crane.Option) error {
+	for i, pkg := range l.Packages {
+		logger.Debugf("Resolving %s", pkg.Package)
+		resolved, err := Resolve(ctx, pkg.Package, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve %s", pkg.Package)
+		}
+		if resolved.Digest != pkg.Digest {
+			logger.Infof("%s updated to %s", pkg.Package, resolved.Digest)
+		}
+		l.Packages[i] = *resolved
+	}
+	return nil
+}
+
+// Locked packages matching references, all packages if no references set
+func (l *Lock) Find(refs []string) ([]Package, error) {
+	if len(refs) == 0 {
+		return l.Packages, nil
+	}
+	found := []Package{}
+	for _, ref := range refs {
+		repo, _ := packages.SplitReference(ref)
+		matched := false
+		for _, pkg := range l.Packages {
+			if pkg.Package == ref || pkg.Repository() == repo {
+				found = append(found, pkg)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("package %s not found in lock file, run 'overlock lock update' to add it", ref)
+		}
+	}
+	return found, nil
+}
+
+// Install locked packages with dependencies by digest, so Crossplane does not
+// resolve other versions of dependencies
+func Install(ctx context.Context, config *rest.Config, pkgs []Package, logger *zap.SugaredLogger) ([]string, error) {
+	installed := []string{}
+	opts := xpkg.InstallOptions{SkipDependencyResolution: true}
+	for _, pkg := range pkgs {
+		for _, dep := range pkg.Dependencies {
+			ref := dep.Package + "@" + dep.Digest
+			if err := installPinned(ctx, config, dep.Kind, ref, opts, logger); err != nil {
+				return installed, err
+			}
+		}
+		ref := pkg.Pinned()
+		if err := installPinned(ctx, config, pkg.Kind, ref, opts, logger); err != nil {
+			return installed, err
+		}
+		installed = append(installed, ref)
+	}
+	return installed, nil
+}
+
+func installPinned(ctx context.Context, config *rest.Config, kind string, ref string, opts xpkg.InstallOptions, logger *zap.SugaredLogger) error {
+	pkg, err := xpkg.New(kind)
+	if err != nil {
+		return err
+	}
+	return pkg.Install(ctx, config, ref, opts, logger)
+}
+
+// Repository of locked package
+func (p *Package) Repository() string {
+	repo, _ := packages.SplitReference(p.Package)
+	return repo
+}
+
+// Reference of locked package by digest
+func (p *Package) Pinned() string {
+	return p.Repository() + "@" + p.Digest
+}
+
+// Kind name in lower case, like provider
+func (p *Package) KindName() string {
+	return strings.ToLower(p.Kind)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/web-seven/overlock/internal/kube"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 )
@@ -34,3 +35,17 @@ func CreateNamespace(ctx context.Context, config *rest.Config) error {
 	}
 	return nil
 }
+
+// Deletes system namespace
+func DeleteNamespace(ctx context.Context, config *rest.Config) error {
+	client, err := kube.Client(config)
+	if err != nil {
+		return err
+	}
+
+	err = client.CoreV1().Namespaces().Delete(ctx, Namespace, v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,354 @@
+package packages
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/web-seven/overlock/internal/image"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	BaseAnnotation     = "base"
+	ExamplesAnnotation = "upbound"
+	PackageFile        = "package.yaml"
+	ExamplesFile       = ".up/examples.yaml"
+	metaGroupSuffix    = "meta.pkg.crossplane.io"
+)
+
+// Package metadata from meta.pkg.crossplane.io document of package.yaml
+type Meta struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		DependsOn []pkgmetav1.Dependency `json:"dependsOn,omitempty"`
+	} `json:"spec"`
+}
+
+// Node of package dependency tree resolved to exact versions
+type Node struct {
+	Kind       string
+	Repository string
+	Constraint string
+	Version    string
+	// Digest of image or of index for multi-platform package
+	Digest string
+	// Image of package, first platform image for multi-platform package
+	Image v1.Image `json:"-"`
+	// Index of multi-platform package, nil for single image
+	Index        v1.ImageIndex `json:"-"`
+	Dependencies []*Node
+}
+
+// Reference of resolved package image
+func (n *Node) Reference() string {
+	if strings.HasPrefix(n.Version, "sha256:") {
+		return n.Repository + "@" + n.Version
+	}
+	return n.Repository + ":" + n.Version
+}
+
+// Walk tree in depth first order, visiting every package once
+func (n *Node) Walk(fn func(*Node) error) error {
+	return n.walk(map[string]bool{}, fn)
+}
+
+func (n *Node) walk(visited map[string]bool, fn func(*Node) error) error {
+	if visited[n.Reference()] {
+		return nil
+	}
+	visited[n.Reference()] = true
+	for _, dep := range n.Dependencies {
+		if err := dep.walk(visited, fn); err != nil {
+			return err
+		}
+	}
+	return fn(n)
+}
+
+// Resolve package reference with its dependency tree. Reference version
+// could be exact tag, digest or semantic version constraint, like v0.x.
+func ResolveTree(ref string, opts ...crane.Option) (*Node, error) {
+	repo, constraint := SplitReference(ref)
+	return resolve(repo, constraint, map[string]*Node{}, opts)
+}
+
+func resolve(repo string, constraint string, resolved map[string]*Node, opts []crane.Option) (*Node, error) {
+	version, err := ResolveVersion(repo, constraint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{Repository: repo, Constraint: constraint, Version: version}
+	if existing, ok := resolved[node.Reference()]; ok {
+		return existing, nil
+	}
+	resolved[node.Reference()] = node
+
+	if err := node.fetch(opts); err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", node.Reference(), err)
+	}
+	meta, err := ReadMeta(node.Image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of %s: %w", node.Reference(), err)
+	}
+	node.Kind = meta.Kind
+
+	for _, dep := range meta.Spec.DependsOn {
+		depRepo := DependencyPackage(dep)
+		if depRepo == "" {
+			continue
+		}
+		depNode, err := resolve(depRepo, dep.Version, resolved, opts)
+		if err != nil {
+			return nil, err
+		}
+		node.Dependencies = append(node.Dependencies, depNode)
+	}
+	return node, nil
+}
+
+// Fetch image of node keeping index of multi-platform package, package
+// metadata is read from image of first platform as it is the same for all
+func (n *Node) fetch(opts []crane.Option) error {
+	o := crane.GetOptions(opts...)
+	ref, err := name.ParseReference(n.Reference(), o.Name...)
+	if err != nil {
+		return err
+	}
+	desc, err := remote.Get(ref, o.Remote...)
+	if err != nil {
+		return err
+	}
+	n.Digest = desc.Digest.String()
+	if !desc.MediaType.IsIndex() {
+		n.Image, err = desc.Image()
+		return err
+	}
+	n.Index, err = desc.ImageIndex()
+	if err != nil {
+		return err
+	}
+	manifest, err := n.Index.IndexManifest()
+	if err != nil {
+		return err
+	}
+	if len(manifest.Manifests) == 0 {
+		return fmt.Errorf("index %s has no images", n.Reference())
+	}
+	n.Image, err = n.Index.Image(manifest.Manifests[0].Digest)
+	return err
+}
+
+// Package image name of dependency, regardless of its kind
+func DependencyPackage(dep pkgmetav1.Dependency) string {
+	for _, pkg := range []*string{dep.Configuration, dep.Provider, dep.Function} {
+		if pkg != nil {
+			return *pkg
+		}
+	}
+	return ""
+}
+
+// Split reference on repository and version, which could be a constraint
+// not allowed by reference syntax.
+func SplitReference(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "@"); i > 0 {
+		return ref[:i], ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Resolve version of repository satisfying constraint to existing tag or digest.
+// Empty constraint resolves to the latest semantic version tag.
+func ResolveVersion(repo string, constraint string, opts ...crane.Option) (string, error) {
+	if strings.HasPrefix(constraint, "sha256:") {
+		return constraint, nil
+	}
+	exact, exactErr := semver.StrictNewVersion(strings.TrimPrefix(constraint, "v"))
+	c, err := semver.NewConstraint(constraint)
+	if constraint == "" {
+		c, err = semver.NewConstraint("*")
+	}
+	// Not a constraint, like latest, use as plain tag
+	plain := exactErr != nil && err != nil
+	if plain {
+		if _, err := name.NewTag(repo + ":" + constraint); err != nil {
+			return "", fmt.Errorf("invalid version %s of %s", constraint, repo)
+		}
+	}
+
+	tags, err := crane.ListTags(repo, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags of %s: %w", repo, err)
+	}
+	if slices.Contains(tags, constraint) && (exactErr == nil || plain) {
+		return constraint, nil
+	}
+	if plain {
+		return "", fmt.Errorf("version %s of %s not found", constraint, repo)
+	}
+	// Exact version matches tag of equal version, like 1.2.3 matches v1.2.3
+	if exactErr == nil {
+		for _, tag := range tags {
+			if v, err := semver.NewVersion(tag); err == nil && v.Equal(exact) {
+				return tag, nil
+			}
+		}
+		return "", fmt.Errorf("version %s of %s not found", constraint, repo)
+	}
+	versions := map[*semver.Version]string{}
+	collection := semver.Collection{}
+	for _, tag := range tags {
+		v, err := semver.NewVersion(tag)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		versions[v] = tag
+		collection = append(collection, v)
+	}
+	if len(collection) == 0 {
+		return "", fmt.Errorf("no version of %s satisfies %s", repo, constraint)
+	}
+	sort.Sort(collection)
+	return versions[collection[len(collection)-1]], nil
+}
+
+// Read package metadata from annotated base layer or flattened image
+func ReadMeta(img v1.Image) (*Meta, error) {
+	content, err := ReadPackageFile(img)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := metaDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	meta := &Meta{}
+	if err := yaml.Unmarshal(doc, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Content of package.yaml from image
+func ReadPackageFile(img v1.Image) ([]byte, error) {
+	layer, err := BaseLayer(img)
+	if err != nil {
+		return nil, err
+	}
+	var rc io.ReadCloser
+	if layer != nil {
+		rc, err = layer.Uncompressed()
+	} else {
+		rc = mutate.Extract(img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in package", PackageFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimPrefix(hdr.Name, "/") == PackageFile {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// Layer annotated as package base, nil when image has no annotated layers
+// or labels
+func BaseLayer(img v1.Image) (v1.Layer, error) {
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range manifest.Layers {
+		if desc.Annotations[image.AnnotationKey] == BaseAnnotation {
+			return img.LayerByDigest(desc.Digest)
+		}
+	}
+	// Annotations are lost in archives, but labels of configuration remain
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	for label, value := range cfg.Config.Labels {
+		digest, ok := strings.CutPrefix(label, image.AnnotationKey+":")
+		if !ok || value != BaseAnnotation {
+			continue
+		}
+		hash, err := v1.NewHash(digest)
+		if err != nil {
+			return nil, err
+		}
+		return img.LayerByDigest(hash)
+	}
+	return nil, nil
+}
+
+// Package metadata document from package.yaml stream
+func metaDocument(content []byte) ([]byte, error) {
+	docs, err := SplitDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if IsMetaDocument(doc) {
+			return doc, nil
+		}
+	}
+	return nil, errors.New("package metadata not found")
+}
+
+// Split YAML stream on documents
+func SplitDocuments(content []byte) ([][]byte, error) {
+	docs := [][]byte{}
+	reader := kyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(content)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// Check if document is package metadata
+func IsMetaDocument(doc []byte) bool {
+	typeMeta := struct {
+		APIVersion string `json:"apiVersion"`
+	}{}
+	if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+		return false
+	}
+	return strings.HasPrefix(typeMeta.APIVersion, metaGroupSuffix+"/")
+}
@@ -0,0 +1,281 @@
+package packages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/crane"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// States of package in dependency graph
+const (
+	StateSatisfied  = "satisfied"
+	StateMissing    = "missing"
+	StateConflict   = "conflict"
+	StateUnresolved = "unresolved"
+)
+
+// Package of dependency graph with state of its installation in cluster.
+// Unlike resolved tree, graph keeps packages which could not be resolved.
+type GraphNode struct {
+	Kind         string       `json:"kind,omitempty"`
+	Repository   string       `json:"repository"`
+	Constraint   string       `json:"constraint,omitempty"`
+	Version      string       `json:"version,omitempty"`
+	Digest       string       `json:"digest,omitempty"`
+	Installed    string       `json:"installed,omitempty"`
+	State        string       `json:"state"`
+	Reason       string       `json:"reason,omitempty"`
+	Dependencies []*GraphNode `json:"dependencies,omitempty"`
+}
+
+// Resolve dependency graph of package reference and compare it with versions
+// installed in cluster, mapped by repository.
+func ResolveGraph(ref string, installed map[string]string, opts ...crane.Option) *GraphNode {
+	repo, constraint := SplitReference(ref)
+	root := resolveGraph(repo, constraint, installed, map[string]bool{}, opts)
+	markConflicts(root)
+	return root
+}
+
+func resolveGraph(repo string, constraint string, installed map[string]string, path map[string]bool, opts []crane.Option) *GraphNode {
+	node := &GraphNode{Repository: repo, Constraint: constraint, Installed: installed[repo]}
+	node.State = installedState(node)
+
+	version, err := ResolveVersion(repo, constraint, opts...)
+	if err != nil {
+		node.State, node.Reason = StateUnresolved, err.Error()
+		return node
+	}
+	node.Version = version
+	ref := (&Node{Repository: repo, Version: version}).Reference()
+	if path[repo] {
+		node.State, node.Reason = StateConflict, "circular dependency"
+		return node
+	}
+
+	fetched := &Node{Repository: repo, Version: version}
+	if err := fetched.fetch(opts); err != nil {
+		node.State, node.Reason = StateUnresolved, fmt.Sprintf("failed to pull %s: %v", ref, err)
+		return node
+	}
+	node.Digest = fetched.Digest
+	node.State = installedState(node)
+	meta, err := ReadMeta(fetched.Image)
+	if err != nil {
+		node.State, node.Reason = StateUnresolved, fmt.Sprintf("failed to read metadata of %s: %v", ref, err)
+		return node
+	}
+	node.Kind = meta.Kind
+
+	path[repo] = true
+	defer delete(path, repo)
+	for _, dep := range meta.Spec.DependsOn {
+		depRepo := DependencyPackage(dep)
+		if depRepo == "" {
+			continue
+		}
+		node.Dependencies = append(node.Dependencies, resolveGraph(depRepo, dep.Version, installed, path, opts))
+	}
+	return node
+}
+
+// Package recorded in Crossplane Lock with dependencies of its revision
+type LockPackage struct {
+	// Name of package revision
+	Name         string           `json:"name"`
+	Type         string           `json:"type"`
+	Source       string           `json:"source"`
+	Version      string           `json:"version"`
+	Dependencies []LockDependency `json:"dependencies,omitempty"`
+}
+
+// Dependency of package recorded in Crossplane Lock
+type LockDependency struct {
+	Package     string `json:"package"`
+	Type        string `json:"type"`
+	Constraints string `json:"constraints"`
+}
+
+var lockGVR = schema.GroupVersionResource{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "locks"}
+
+const lockName = "lock"
+
+// Packages of Crossplane Lock, which records dependencies of all installed
+// package revisions
+func ReadLock(ctx context.Context, dc dynamic.Interface) ([]LockPackage, error) {
+	obj, err := dc.Resource(lockGVR).Get(ctx, lockName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Crossplane lock: %w", err)
+	}
+	lock := struct {
+		Packages []LockPackage `json:"packages"`
+	}{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &lock); err != nil {
+		return nil, err
+	}
+	return lock.Packages, nil
+}
+
+// Dependency graph of installed package revision from Crossplane Lock,
+// without pulling packages from registries
+func LockGraph(revision string, lock []LockPackage) (*GraphNode, error) {
+	bySource := map[string]*LockPackage{}
+	var root *LockPackage
+	for i := range lock {
+		bySource[lock[i].Source] = &lock[i]
+		if lock[i].Name == revision {
+			root = &lock[i]
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("revision %s not found in Crossplane lock", revision)
+	}
+	node := lockGraph(root, "", bySource, map[string]bool{})
+	markConflicts(node)
+	return node, nil
+}
+
+func lockGraph(pkg *LockPackage, constraint string, bySource map[string]*LockPackage, path map[string]bool) *GraphNode {
+	node := &GraphNode{
+		Kind:       pkg.Type,
+		Repository: pkg.Source,
+		Constraint: constraint,
+		Version:    pkg.Version,
+		Installed:  pkg.Version,
+	}
+	if strings.HasPrefix(pkg.Version, "sha256:") {
+		node.Digest = pkg.Version
+	}
+	node.State = installedState(node)
+	if path[pkg.Source] {
+		node.State, node.Reason = StateConflict, "circular dependency"
+		return node
+	}
+	path[pkg.Source] = true
+	defer delete(path, pkg.Source)
+	for _, dep := range pkg.Dependencies {
+		installed, ok := bySource[dep.Package]
+		if !ok {
+			missing := &GraphNode{Kind: dep.Type, Repository: dep.Package, Constraint: dep.Constraints}
+			missing.State = installedState(missing)
+			node.Dependencies = append(node.Dependencies, missing)
+			continue
+		}
+		node.Dependencies = append(node.Dependencies, lockGraph(installed, dep.Constraints, bySource, path))
+	}
+	return node
+}
+
+// State of package by installed version, without regard to other dependents
+func installedState(node *GraphNode) string {
+	node.Reason = ""
+	switch {
+	case node.Installed == "":
+		return StateMissing
+	case Satisfies(node.Installed, node.Constraint) || node.Installed == node.Digest:
+		return StateSatisfied
+	default:
+		node.Reason = fmt.Sprintf("installed %s does not satisfy %s", node.Installed, node.Constraint)
+		return StateConflict
+	}
+}
+
+// Mark packages required by different dependents in versions which could not
+// be satisfied by the same package
+func markConflicts(root *GraphNode) {
+	nodes := map[string][]*GraphNode{}
+	root.walk(func(n *GraphNode) {
+		nodes[n.Repository] = append(nodes[n.Repository], n)
+	})
+	for _, required := range nodes {
+		for _, n := range required {
+			if n.Version == "" || n.State == StateUnresolved {
+				continue
+			}
+			for _, other := range required {
+				if other == n || Satisfies(n.Version, other.Constraint) {
+					continue
+				}
+				n.State = StateConflict
+				n.Reason = fmt.Sprintf("%s required as %s by another package", n.Repository, other.Constraint)
+				break
+			}
+		}
+	}
+}
+
+func (n *GraphNode) walk(fn func(*GraphNode)) {
+	fn(n)
+	for _, dep := range n.Dependencies {
+		dep.walk(fn)
+	}
+}
+
+// Check if version or digest satisfies constraint, empty constraint is
+// satisfied by any version
+func Satisfies(version string, constraint string) bool {
+	if constraint == "" || version == constraint {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// Graph in DOT language of Graphviz
+func (n *GraphNode) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  node [shape=box];\n")
+	written := map[string]bool{}
+	n.walk(func(node *GraphNode) {
+		if written[node.Repository] {
+			return
+		}
+		written[node.Repository] = true
+		label := node.Repository
+		if node.Version != "" {
+			label += "\\n" + node.Version
+		}
+		if node.Installed != "" {
+			label += "\\ninstalled: " + node.Installed
+		}
+		fmt.Fprintf(&b, "  %q [label=\"%s\", color=%s];\n", node.Repository, label, stateColor(node.State))
+	})
+	edges := map[string]bool{}
+	n.walk(func(node *GraphNode) {
+		for _, dep := range node.Dependencies {
+			edge := fmt.Sprintf("  %q -> %q [label=%q];\n", node.Repository, dep.Repository, dep.Constraint)
+			if !edges[edge] {
+				edges[edge] = true
+				b.WriteString(edge)
+			}
+		}
+	})
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func stateColor(state string) string {
+	switch state {
+	case StateSatisfied:
+		return "green"
+	case StateMissing:
+		return "orange"
+	default:
+		return "red"
+	}
+}
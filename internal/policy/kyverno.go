@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/web-seven/overlock/internal/install/helm"
+	"github.com/web-seven/overlock/internal/kube"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,9 +22,18 @@ const (
 	kyvernoReleaseName  = "kyverno"
 	kyvernoRepoUrl      = "https://kyverno.github.io/kyverno/"
 	kyvernoNamespace    = "kyverno"
+	kyvernoCRDGroup     = "kyverno.io"
+	policyNamePrefix    = "overlock."
+	verifyPolicyName    = policyNamePrefix + "verify-packages"
 )
 
 var (
+	podContainerLists = []string{"containers", "initContainers", "ephemeralContainers"}
+	clusterPolicyGVR  = schema.GroupVersionResource{
+		Group:    "kyverno.io",
+		Version:  "v1",
+		Resource: "clusterpolicies",
+	}
 	chartValues = map[string]interface{}{
 		"cleanupController": map[string]interface{}{
 			"enabled": false,
@@ -82,44 +94,213 @@ func addKyvernoDefaultPolicies(ctx context.Context, config *rest.Config) error {
 
 // Add registry policies to sync and apply image pull secrets
 func addKyvernoRegistryPolicies(ctx context.Context, config *rest.Config, registry *RegistryPolicy) error {
+	name := policyNamePrefix + registry.Name
+	rules := []interface{}{}
+	for _, list := range podContainerLists {
+		rules = append(rules, registryRewriteRule(name, list, registry))
+	}
 
 	regplc := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": "kyverno.io/v1",
 			"kind":       "ClusterPolicy",
 			"metadata": map[string]interface{}{
-				"name": "overlock." + registry.Name,
+				"name": name,
 				"annotations": map[string]interface{}{
 					"pod-policies.kyverno.io/autogen-controllers": "none",
 				},
 			},
 			"spec": map[string]interface{}{
 				"generateExisting": true,
+				"rules":            rules,
+			},
+		},
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	deleteKyvernoRegistryPolicies(ctx, config, registry)
+
+	_, err = dynamicClient.Resource(clusterPolicyGVR).Create(ctx, regplc, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Rule rewriting registry of images in one container list of Pod to node port
+// of local registry. Rules of optional lists are skipped for Pods without them.
+func registryRewriteRule(name string, list string, registry *RegistryPolicy) map[string]interface{} {
+	kinds := []interface{}{"Pod"}
+	if list == "ephemeralContainers" {
+		kinds = append(kinds, "Pod/ephemeralcontainers")
+	}
+	resources := map[string]interface{}{
+		"kinds": kinds,
+	}
+	if len(registry.Namespaces) > 0 {
+		namespaces := []interface{}{}
+		for _, ns := range registry.Namespaces {
+			namespaces = append(namespaces, ns)
+		}
+		resources["namespaces"] = namespaces
+	}
+	if len(registry.Labels) > 0 {
+		labels := map[string]interface{}{}
+		for k, v := range registry.Labels {
+			labels[k] = v
+		}
+		resources["selector"] = map[string]interface{}{
+			"matchLabels": labels,
+		}
+	}
+
+	rule := map[string]interface{}{
+		"name": name + "." + strings.ToLower(list),
+		"match": map[string]interface{}{
+			"any": []interface{}{
+				map[string]interface{}{
+					"resources": resources,
+				},
+			},
+		},
+		"skipBackgroundRequests": false,
+		"mutate": map[string]interface{}{
+			"foreach": []interface{}{
+				map[string]interface{}{
+					"list": "request.object.spec." + list,
+					"patchStrategicMerge": map[string]interface{}{
+						"spec": map[string]interface{}{
+							list: []interface{}{
+								map[string]interface{}{
+									"(image)": fmt.Sprintf("*%s*", registry.Url),
+									"image":   fmt.Sprintf("{{ regex_replace_all_literal('^[^/]+', '{{element.image}}', 'localhost:%s' )}}", registry.NodePort),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if list != "containers" {
+		rule["preconditions"] = map[string]interface{}{
+			"all": []interface{}{
+				map[string]interface{}{
+					"key":      fmt.Sprintf("{{ request.object.spec.%s[] || `[]` | length(@) }}", list),
+					"operator": "GreaterThanOrEquals",
+					"value":    1,
+				},
+			},
+		}
+	}
+	return rule
+}
+
+// Get policy of registry as applied in cluster
+func getKyvernoRegistryPolicy(ctx context.Context, config *rest.Config, name string) (*unstructured.Unstructured, error) {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return dynamicClient.Resource(clusterPolicyGVR).Get(ctx, policyNamePrefix+name, metav1.GetOptions{})
+}
+
+// Add policy verifying signatures of Crossplane packages before they are
+// admitted. Kyverno reaches local registry with self-signed certificate,
+// so insecure registries are allowed for the admission controller.
+func addKyvernoVerifyImagesPolicy(ctx context.Context, config *rest.Config, verify *VerifyImagesPolicy) error {
+	repoURL, err := url.Parse(kyvernoRepoUrl)
+	if err != nil {
+		return err
+	}
+	manager, err := helm.NewManager(config, kyvernoChartName, repoURL, kyvernoReleaseName,
+		helm.InstallerModifierFn(helm.Wait()),
+		helm.InstallerModifierFn(helm.WithNamespace(kyvernoNamespace)),
+		helm.InstallerModifierFn(helm.WithUpgradeInstall(true)),
+	)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{
+		"admissionController": map[string]interface{}{
+			"container": map[string]interface{}{
+				"extraArgs": map[string]interface{}{
+					"allowInsecureRegistry": true,
+				},
+			},
+		},
+	}
+	for k, v := range chartValues {
+		values[k] = v
+	}
+	err = manager.Upgrade(kyvernoChartVersion, values)
+	if err != nil {
+		return err
+	}
+
+	imageReferences := []interface{}{}
+	for _, ref := range verify.ImageReferences {
+		imageReferences = append(imageReferences, ref)
+	}
+	packagePath := []interface{}{
+		map[string]interface{}{"path": "/spec/package"},
+	}
+	plc := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "kyverno.io/v1",
+			"kind":       "ClusterPolicy",
+			"metadata": map[string]interface{}{
+				"name": verifyPolicyName,
+			},
+			"spec": map[string]interface{}{
+				"validationFailureAction": "Enforce",
+				"webhookTimeoutSeconds":   int64(30),
 				"rules": []interface{}{
 					map[string]interface{}{
-						"name": "overlock." + registry.Name,
+						"name": verifyPolicyName,
 						"match": map[string]interface{}{
 							"any": []interface{}{
 								map[string]interface{}{
 									"resources": map[string]interface{}{
 										"kinds": []interface{}{
-											"Pod",
+											"pkg.crossplane.io/*/Provider",
+											"pkg.crossplane.io/*/Configuration",
+											"pkg.crossplane.io/*/Function",
 										},
 									},
 								},
 							},
 						},
-						"skipBackgroundRequests": false,
-						"mutate": map[string]interface{}{
-							"foreach": []interface{}{
-								map[string]interface{}{
-									"list": "request.object.spec.containers",
-									"patchStrategicMerge": map[string]interface{}{
-										"spec": map[string]interface{}{
-											"containers": []interface{}{
-												map[string]interface{}{
-													"(image)": fmt.Sprintf("*%s*", registry.Url),
-													"image":   fmt.Sprintf("{{ regex_replace_all_literal('^[^/]+', '{{element.image}}', 'localhost:%s' )}}", registry.NodePort),
+						"imageExtractors": map[string]interface{}{
+							"Provider":      packagePath,
+							"Configuration": packagePath,
+							"Function":      packagePath,
+						},
+						"verifyImages": []interface{}{
+							map[string]interface{}{
+								"imageReferences": imageReferences,
+								"mutateDigest":    false,
+								"verifyDigest":    false,
+								"required":        true,
+								"imageRegistryCredentials": map[string]interface{}{
+									"allowInsecureRegistry": true,
+								},
+								"attestors": []interface{}{
+									map[string]interface{}{
+										"entries": []interface{}{
+											map[string]interface{}{
+												"keys": map[string]interface{}{
+													"publicKeys": verify.PublicKey,
+													"rekor": map[string]interface{}{
+														"ignoreTlog": true,
+													},
+													"ctlog": map[string]interface{}{
+														"ignoreSCT": true,
+													},
 												},
 											},
 										},
@@ -133,6 +314,20 @@ func addKyvernoRegistryPolicies(ctx context.Context, config *rest.Config, regist
 		},
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	err = dynamicClient.Resource(clusterPolicyGVR).Delete(ctx, verifyPolicyName, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	_, err = dynamicClient.Resource(clusterPolicyGVR).Create(ctx, plc, metav1.CreateOptions{})
+	return err
+}
+
+// Delete policies of removed registry
+func deleteKyvernoRegistryPolicies(ctx context.Context, config *rest.Config, registry *RegistryPolicy) error {
 	dynamicClient, err := dynamic.NewForConfig(config)
 	if err != nil {
 		return err
@@ -143,32 +338,81 @@ func addKyvernoRegistryPolicies(ctx context.Context, config *rest.Config, regist
 		Version:  "v1",
 		Resource: "clusterpolicies",
 	}
+	scplcName := "overlock." + registry.Name
+	err = dynamicClient.Resource(gvr).Delete(ctx, scplcName, metav1.DeleteOptions{})
+	if err != nil {
+		return err
+	}
+	return nil
+}
 
-	deleteKyvernoRegistryPolicies(ctx, config, registry)
+// Delete all cluster policies created by overlock
+func deleteKyvernoManagedPolicies(ctx context.Context, config *rest.Config) error {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
 
-	_, err = dynamicClient.Resource(gvr).Create(ctx, regplc, metav1.CreateOptions{})
+	policies, err := kube.GetKubeResources(kube.ResourceParams{
+		Dynamic:  dynamicClient,
+		Ctx:      ctx,
+		Group:    clusterPolicyGVR.Group,
+		Version:  clusterPolicyGVR.Version,
+		Resource: clusterPolicyGVR.Resource,
+	})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+
+	for _, plc := range policies {
+		if !strings.HasPrefix(plc.GetName(), policyNamePrefix) {
+			continue
+		}
+		err := dynamicClient.Resource(clusterPolicyGVR).Delete(ctx, plc.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
 	return nil
 }
 
-// Delete policies of removed registry
-func deleteKyvernoRegistryPolicies(ctx context.Context, config *rest.Config, registry *RegistryPolicy) error {
-	dynamicClient, err := dynamic.NewForConfig(config)
+// Uninstall Kyverno release, its CRDs and namespace
+func removeKyvernoPolicyController(ctx context.Context, config *rest.Config) error {
+	repoURL, err := url.Parse(kyvernoRepoUrl)
 	if err != nil {
 		return err
 	}
 
-	gvr := schema.GroupVersionResource{
-		Group:    "kyverno.io",
-		Version:  "v1",
-		Resource: "clusterpolicies",
+	manager, err := helm.NewManager(config, kyvernoChartName, repoURL, kyvernoReleaseName,
+		helm.InstallerModifierFn(helm.Wait()),
+		helm.InstallerModifierFn(helm.WithNamespace(kyvernoNamespace)),
+	)
+	if err != nil {
+		return err
 	}
-	scplcName := "overlock." + registry.Name
-	err = dynamicClient.Resource(gvr).Delete(ctx, scplcName, metav1.DeleteOptions{})
+
+	release, _ := manager.GetRelease()
+	if release != nil {
+		if err := manager.Uninstall(); err != nil {
+			return err
+		}
+	}
+
+	err = kube.DeleteCustomResourceDefinitions(ctx, config, kyvernoCRDGroup, "wgpolicyk8s.io")
+	if err != nil {
+		return err
+	}
+
+	client, err := kube.Client(config)
 	if err != nil {
 		return err
 	}
+	err = client.CoreV1().Namespaces().Delete(ctx, kyvernoNamespace, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
 	return nil
 }
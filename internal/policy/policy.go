@@ -3,15 +3,24 @@ package policy
 import (
 	"context"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/rest"
 )
 
 const DefaultPolicyController = "kyverno"
 
 type RegistryPolicy struct {
-	Name     string
-	Url      string
-	NodePort string
+	Name       string
+	Url        string
+	NodePort   string
+	Namespaces []string
+	Labels     map[string]string
+}
+
+// Signature verification of Crossplane packages
+type VerifyImagesPolicy struct {
+	PublicKey       string
+	ImageReferences []string
 }
 
 // Add policy controller
@@ -27,12 +36,40 @@ func AddPolicyConroller(ctx context.Context, config *rest.Config, plcType string
 	return nil
 }
 
+// Remove policy controller and all overlock policies
+func RemovePolicyController(ctx context.Context, config *rest.Config, plcType string) error {
+	switch plcType {
+	case "kyverno":
+		err := deleteKyvernoManagedPolicies(ctx, config)
+		if err != nil {
+			return err
+		}
+		return removeKyvernoPolicyController(ctx, config)
+	}
+	return nil
+}
+
+// Delete all policies created by overlock
+func DeleteManagedPolicies(ctx context.Context, config *rest.Config) error {
+	return deleteKyvernoManagedPolicies(ctx, config)
+}
+
 // Add registry related policies
 func AddRegistryPolicy(ctx context.Context, config *rest.Config, registry *RegistryPolicy) error {
 	return addKyvernoRegistryPolicies(ctx, config, registry)
 }
 
+// Get registry policy applied in cluster
+func GetRegistryPolicy(ctx context.Context, config *rest.Config, name string) (*unstructured.Unstructured, error) {
+	return getKyvernoRegistryPolicy(ctx, config, name)
+}
+
 // Delete registry related policies
 func DeleteRegistryPolicy(ctx context.Context, config *rest.Config, registry *RegistryPolicy) error {
 	return deleteKyvernoRegistryPolicies(ctx, config, registry)
 }
+
+// Allow only packages signed with public key
+func AddVerifyImagesPolicy(ctx context.Context, config *rest.Config, verify *VerifyImagesPolicy) error {
+	return addKyvernoVerifyImagesPolicy(ctx, config, verify)
+}
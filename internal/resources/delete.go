@@ -0,0 +1,199 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/kube"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+const orphanPatch = `{"spec":{"deletionPolicy":"Orphan"}}`
+
+// DeleteXResources deletes claims and composite resources of every XRD in the
+// cluster and waits until they and their composed resources are removed.
+// When orphan is set, composed resources are switched to the Orphan deletion
+// policy first, so external resources stay in place.
+func DeleteXResources(ctx context.Context, config *rest.Config, orphan bool, timeout time.Duration, logger *zap.SugaredLogger) error {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	xrds, err := kube.GetKubeResources(kube.ResourceParams{
+		Dynamic:  dynamicClient,
+		Ctx:      ctx,
+		Group:    "apiextensions.crossplane.io",
+		Version:  "v1",
+		Resource: "compositeresourcedefinitions",
+	})
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var pending []kube.ResourceParams
+	var composed []corev1.ObjectReference
+	for _, item := range xrds {
+		xrd := v1.CompositeResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &xrd); err != nil {
+			logger.Warnf("Failed to convert XRD %s: %v", item.GetName(), err)
+			continue
+		}
+		version := referenceableVersion(xrd)
+
+		if xrd.Spec.ClaimNames != nil {
+			claims := kube.ResourceParams{
+				Dynamic:  dynamicClient,
+				Ctx:      ctx,
+				Group:    xrd.Spec.Group,
+				Version:  version,
+				Resource: xrd.Spec.ClaimNames.Plural,
+			}
+			if err := deleteAll(ctx, claims, logger); err != nil {
+				return err
+			}
+			pending = append(pending, claims)
+		}
+
+		xrs := kube.ResourceParams{
+			Dynamic:  dynamicClient,
+			Ctx:      ctx,
+			Group:    xrd.Spec.Group,
+			Version:  version,
+			Resource: xrd.Spec.Names.Plural,
+		}
+		items, err := kube.GetKubeResources(xrs)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		for _, xr := range items {
+			refs := (&composite.Unstructured{Unstructured: xr}).GetResourceReferences()
+			composed = append(composed, refs...)
+			if orphan {
+				orphanResources(ctx, dynamicClient, mapper, refs, logger)
+			}
+		}
+		if err := deleteAll(ctx, xrs, logger); err != nil {
+			return err
+		}
+		pending = append(pending, xrs)
+	}
+
+	for _, params := range pending {
+		logger.Debugf("Waiting for %s to be deleted", params.Resource)
+		if err := kube.WaitKubeResourcesDeleted(ctx, params, timeout); err != nil {
+			return err
+		}
+	}
+
+	return waitComposedDeleted(ctx, dynamicClient, mapper, composed, timeout, logger)
+}
+
+// Delete every resource of the given type in all namespaces
+func deleteAll(ctx context.Context, params kube.ResourceParams, logger *zap.SugaredLogger) error {
+	items, err := kube.GetKubeResources(params)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	gvr := schema.GroupVersionResource{Group: params.Group, Version: params.Version, Resource: params.Resource}
+	for _, item := range items {
+		logger.Debugf("Deleting %s %s", item.GetKind(), item.GetName())
+		err := params.Dynamic.Resource(gvr).Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Switch composed resources to Orphan deletion policy
+func orphanResources(ctx context.Context, dc dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, refs []corev1.ObjectReference, logger *zap.SugaredLogger) {
+	for _, ref := range refs {
+		gvr, err := refResource(mapper, ref)
+		if err != nil {
+			logger.Debugf("Skip orphaning %s %s: %v", ref.Kind, ref.Name, err)
+			continue
+		}
+		_, err = dc.Resource(gvr).Namespace(ref.Namespace).Patch(ctx, ref.Name, types.MergePatchType, []byte(orphanPatch), metav1.PatchOptions{})
+		if err != nil {
+			logger.Debugf("Skip orphaning %s %s: %v", ref.Kind, ref.Name, err)
+		}
+	}
+}
+
+// Wait until composed resources are removed by their providers
+func waitComposedDeleted(ctx context.Context, dc dynamic.Interface, mapper *restmapper.DeferredDiscoveryRESTMapper, refs []corev1.ObjectReference, timeout time.Duration, logger *zap.SugaredLogger) error {
+	deadline := time.Now().Add(timeout)
+	for _, ref := range refs {
+		gvr, err := refResource(mapper, ref)
+		if err != nil {
+			continue
+		}
+		for {
+			_, err := dc.Resource(gvr).Namespace(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				break
+			}
+			if time.Now().After(deadline) {
+				return errors.Errorf("timeout waiting for %s %s to be deleted", ref.Kind, ref.Name)
+			}
+			logger.Debugf("Waiting for %s %s to be deleted", ref.Kind, ref.Name)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+	}
+	return nil
+}
+
+func refResource(mapper *restmapper.DeferredDiscoveryRESTMapper, ref corev1.ObjectReference) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	mapping, err := mapper.RESTMapping(gv.WithKind(ref.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return mapping.Resource, nil
+}
+
+// Version of XRD used to reference composite resources
+func referenceableVersion(xrd v1.CompositeResourceDefinition) string {
+	for _, version := range xrd.Spec.Versions {
+		if version.Referenceable {
+			return version.Name
+		}
+	}
+	if len(xrd.Spec.Versions) > 0 {
+		return xrd.Spec.Versions[0].Name
+	}
+	return ""
+}
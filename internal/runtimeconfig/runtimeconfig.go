@@ -0,0 +1,181 @@
+package runtimeconfig
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/xpkg"
+)
+
+const (
+	kind = "DeploymentRuntimeConfig"
+	// Container of package deployment merged with runtime config by Crossplane
+	runtimeContainer = "package-runtime"
+	debugArg         = "--debug"
+)
+
+var gvr = schema.GroupVersionResource{
+	Group:    "pkg.crossplane.io",
+	Version:  "v1beta1",
+	Resource: "deploymentruntimeconfigs",
+}
+
+// Shortcuts for settings of package runtime deployment
+type Options struct {
+	Debug bool
+	// Environment variables as KEY=VALUE
+	Env      []string
+	CPU      string
+	Memory   string
+	Replicas int
+}
+
+// Create runtime config with options
+func Create(ctx context.Context, dc dynamic.Interface, name string, opts Options, logger *zap.SugaredLogger) error {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": gvr.GroupVersion().String(),
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	if err := apply(obj, opts); err != nil {
+		return err
+	}
+	_, err := dc.Resource(gvr).Create(ctx, obj, metav1.CreateOptions{})
+	if kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("runtime config %s already exists, change it with runtime-config set", name)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to create runtime config %s", name)
+	}
+	logger.Infof("Runtime config %s created.", name)
+	return nil
+}
+
+// Change existing runtime config with options
+func Set(ctx context.Context, dc dynamic.Interface, name string, opts Options, logger *zap.SugaredLogger) error {
+	obj, err := dc.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return fmt.Errorf("runtime config %s not found", name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := apply(obj, opts); err != nil {
+		return err
+	}
+	if _, err := dc.Resource(gvr).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update runtime config %s", name)
+	}
+	logger.Infof("Runtime config %s updated.", name)
+	return nil
+}
+
+// Reference existing runtime config from installed provider or function
+func Attach(ctx context.Context, dc dynamic.Interface, pkg xpkg.Package, ref string, name string, logger *zap.SugaredLogger) error {
+	if _, err := dc.Resource(gvr).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if kerrors.IsNotFound(err) {
+			return fmt.Errorf("runtime config %s not found", name)
+		}
+		return err
+	}
+	if err := pkg.SetRuntimeConfig(ctx, dc, ref, name); err != nil {
+		return errors.Wrapf(err, "failed to attach runtime config %s", name)
+	}
+	logger.Infof("Runtime config %s attached to %s %s.", name, pkg.Kind(), ref)
+	return nil
+}
+
+// Apply options to deployment template of runtime config
+func apply(obj *unstructured.Unstructured, opts Options) error {
+	deployment := []string{"spec", "deploymentTemplate", "spec"}
+	if _, found, _ := unstructured.NestedMap(obj.Object, append(deployment, "selector")...); !found {
+		if err := unstructured.SetNestedMap(obj.Object, map[string]interface{}{}, append(deployment, "selector")...); err != nil {
+			return err
+		}
+	}
+	if opts.Replicas > 0 {
+		if err := unstructured.SetNestedField(obj.Object, int64(opts.Replicas), append(deployment, "replicas")...); err != nil {
+			return err
+		}
+	}
+
+	containersPath := append(deployment, "template", "spec", "containers")
+	containers, _, err := unstructured.NestedSlice(obj.Object, containersPath...)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(containers, func(c interface{}) bool {
+		container, ok := c.(map[string]interface{})
+		return ok && container["name"] == runtimeContainer
+	})
+	if index < 0 {
+		containers = append(containers, map[string]interface{}{"name": runtimeContainer})
+		index = len(containers) - 1
+	}
+	container := containers[index].(map[string]interface{})
+	if err := applyContainer(container, opts); err != nil {
+		return err
+	}
+	containers[index] = container
+	return unstructured.SetNestedSlice(obj.Object, containers, containersPath...)
+}
+
+func applyContainer(container map[string]interface{}, opts Options) error {
+	if opts.Debug {
+		args, _, _ := unstructured.NestedStringSlice(container, "args")
+		if !slices.Contains(args, debugArg) {
+			if err := unstructured.SetNestedStringSlice(container, append(args, debugArg), "args"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(opts.Env) > 0 {
+		env, _, err := unstructured.NestedSlice(container, "env")
+		if err != nil {
+			return err
+		}
+		for _, e := range opts.Env {
+			key, value, ok := strings.Cut(e, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid environment variable %s, expected KEY=VALUE", e)
+			}
+			env = slices.DeleteFunc(env, func(v interface{}) bool {
+				existing, ok := v.(map[string]interface{})
+				return ok && existing["name"] == key
+			})
+			env = append(env, map[string]interface{}{"name": key, "value": value})
+		}
+		if err := unstructured.SetNestedSlice(container, env, "env"); err != nil {
+			return err
+		}
+	}
+
+	for name, quantity := range map[string]string{"cpu": opts.CPU, "memory": opts.Memory} {
+		if quantity == "" {
+			continue
+		}
+		if _, err := resource.ParseQuantity(quantity); err != nil {
+			return errors.Wrapf(err, "invalid %s quantity %s", name, quantity)
+		}
+		if err := unstructured.SetNestedField(container, quantity, "resources", "limits", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
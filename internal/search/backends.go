@@ -0,0 +1,158 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/github"
+	"github.com/web-seven/overlock/internal/packages"
+	"github.com/web-seven/overlock/pkg/registry"
+)
+
+// Generic OCI registry searched with catalog and tags API
+type ociBackend struct {
+	host   string
+	path   string
+	auth   authn.Authenticator
+	logger *zap.SugaredLogger
+}
+
+func newOCIBackend(r *registry.Registry, server string, logger *zap.SugaredLogger) *ociBackend {
+	host, path := splitServer(server)
+	b := &ociBackend{host: host, path: path, auth: authn.Anonymous, logger: logger}
+	if auth, err := r.Auth(); err == nil {
+		b.auth = &authn.Basic{Username: auth.Username, Password: auth.Password}
+	}
+	return b
+}
+
+func (b *ociBackend) Name() string {
+	return b.host
+}
+
+func (b *ociBackend) Search(ctx context.Context, query string, versions bool) ([]Result, error) {
+	reg, err := name.NewRegistry(b.host)
+	if err != nil {
+		return nil, err
+	}
+	opts := []remote.Option{remote.WithContext(ctx), remote.WithAuth(b.auth)}
+	repos, err := remote.Catalog(ctx, reg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []Result{}
+	for _, repoName := range repos {
+		if !strings.HasPrefix(repoName, b.path) || !strings.Contains(repoName, query) {
+			continue
+		}
+		repo := reg.Repo(repoName)
+		tags, err := remote.List(repo, opts...)
+		if err != nil || len(tags) == 0 {
+			b.logger.Debugf("Cannot list tags of %s: %v", repo.Name(), err)
+			continue
+		}
+		sortVersions(tags)
+		results = append(results, Result{
+			Package:  repo.Name(),
+			Versions: tags,
+			Kind:     packageKind(repo.Tag(tags[0]), opts...),
+			Source:   b.Name(),
+		})
+	}
+	return results, nil
+}
+
+// In cluster local registry
+type localBackend struct {
+	config *rest.Config
+	logger *zap.SugaredLogger
+}
+
+func (b *localBackend) Name() string {
+	return registry.LocalRegistryName
+}
+
+func (b *localBackend) Search(ctx context.Context, query string, versions bool) ([]Result, error) {
+	images, err := registry.SearchLocalImages(ctx, query, b.config, b.logger)
+	if err != nil {
+		return nil, err
+	}
+	local := registry.NewLocal()
+	results := []Result{}
+	for _, img := range images {
+		sortVersions(img.Tags)
+		results = append(results, Result{
+			Package:  local.LocalDomain() + "/" + img.Repository,
+			Versions: img.Tags,
+			Kind:     img.Kind,
+			Source:   b.Name(),
+		})
+	}
+	return results, nil
+}
+
+// GitHub Container Registry of organization or user
+type githubBackend struct {
+	owner  string
+	token  string
+	auth   authn.Authenticator
+	logger *zap.SugaredLogger
+}
+
+func newGithubBackend(r *registry.Registry, server string, logger *zap.SugaredLogger) *githubBackend {
+	_, owner := splitServer(server)
+	b := &githubBackend{owner: owner, auth: authn.Anonymous, logger: logger}
+	if auth, err := r.Auth(); err == nil {
+		b.token = auth.Password
+		b.auth = &authn.Basic{Username: auth.Username, Password: auth.Password}
+	}
+	return b
+}
+
+func (b *githubBackend) Name() string {
+	return "ghcr.io/" + b.owner
+}
+
+func (b *githubBackend) Search(ctx context.Context, query string, versions bool) ([]Result, error) {
+	pkgs, err := github.GetPackages(ctx, query, versions, b.token, b.owner, b.logger)
+	if err != nil {
+		return nil, err
+	}
+	results := []Result{}
+	for _, pkg := range pkgs {
+		if len(pkg.Versions) == 0 {
+			continue
+		}
+		repo, err := name.NewRepository("ghcr.io/" + b.owner + "/" + pkg.Name)
+		if err != nil {
+			continue
+		}
+		results = append(results, Result{
+			Package:  repo.Name(),
+			Versions: pkg.Versions,
+			Kind:     packageKind(repo.Tag(pkg.Versions[0]), remote.WithContext(ctx), remote.WithAuth(b.auth)),
+			Source:   b.Name(),
+		})
+	}
+	return results, nil
+}
+
+// Kind of package from its metadata in annotated base layer, empty if unknown
+func packageKind(ref name.Reference, opts ...remote.Option) string {
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return ""
+	}
+	meta, err := packages.ReadMeta(img)
+	if err != nil {
+		return ""
+	}
+	return meta.Kind
+}
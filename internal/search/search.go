@@ -3,18 +3,36 @@ package search
 import (
 	"context"
 	"net/url"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
+	semver "github.com/Masterminds/semver/v3"
 	"github.com/pterm/pterm"
 	"go.uber.org/zap"
 
-	"github.com/web-seven/overlock/internal/github"
-	overlockerrors "github.com/web-seven/overlock/pkg/errors"
 	"github.com/web-seven/overlock/pkg/registry"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
+// Package found by search backend
+type Result struct {
+	Package  string
+	Versions []string
+	Kind     string
+	Source   string
+}
+
+// Source of packages which could be searched
+type Backend interface {
+	Name() string
+	Search(ctx context.Context, query string, versions bool) ([]Result, error)
+}
+
+// SearchPackages fans out query to all configured registries, returning merged
+// and ranked results as table.
 func SearchPackages(ctx context.Context, client *kubernetes.Clientset, config *rest.Config, query string, versions bool, logger *zap.SugaredLogger) (pterm.TableData, error) {
 	registries, err := registry.Registries(ctx, client)
 	if err != nil {
@@ -22,22 +40,143 @@ func SearchPackages(ctx context.Context, client *kubernetes.Clientset, config *r
 		return nil, err
 	}
 
+	backends := []Backend{}
 	for _, r := range registries {
-		registryUrl := r.Annotations["overlock-registry-server-url"]
-		u, _ := url.Parse(registryUrl)
-		org := strings.TrimPrefix(u.Path, "/")
-		// Switch statement to handle different registry types
+		registryUrl := r.Annotations[registry.RegistryServerLabel]
 		switch {
+		case r.GetName() == registry.LocalRegistryName:
+			backends = append(backends, &localBackend{config: config, logger: logger})
 		case strings.Contains(registryUrl, "ghcr.io"):
-			tableRegs, err := github.GetPackages(ctx, query, versions, r, registryUrl, org, logger)
+			backends = append(backends, newGithubBackend(r, registryUrl, logger))
+		default:
+			backends = append(backends, newOCIBackend(r, registryUrl, logger))
+		}
+	}
+	results := fanOut(ctx, backends, query, versions, logger)
+	results = rank(merge(results), query)
+
+	tableRegs := pterm.TableData{
+		{"PACKAGE", "VERSION", "KIND", "SOURCE"},
+	}
+	for _, res := range results {
+		if !versions && len(res.Versions) > 1 {
+			res.Versions = res.Versions[:1]
+		}
+		for _, v := range res.Versions {
+			tableRegs = append(tableRegs, []string{res.Package, v, res.Kind, res.Source})
+		}
+	}
+	return tableRegs, nil
+}
+
+// Run search on all backends concurrently, failed backends are reported and skipped
+func fanOut(ctx context.Context, backends []Backend, query string, versions bool, logger *zap.SugaredLogger) []Result {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []Result
+	)
+	for _, b := range backends {
+		wg.Add(1)
+		go func(b Backend) {
+			defer wg.Done()
+			found, err := b.Search(ctx, query, versions)
 			if err != nil {
-				return nil, err
+				logger.Warnf("Search in %s failed: %v", b.Name(), err)
+				return
 			}
-			return tableRegs, nil
-		default:
-			return nil, overlockerrors.NewPackageNotFoundError(query, registryUrl, "", "registry type not supported for package search")
+			mu.Lock()
+			results = append(results, found...)
+			mu.Unlock()
+		}(b)
+	}
+	wg.Wait()
+	return results
+}
+
+// Merge results of the same package, joining versions and keeping known kind
+func merge(results []Result) []Result {
+	merged := map[string]*Result{}
+	order := []string{}
+	for _, res := range results {
+		existing, ok := merged[res.Package]
+		if !ok {
+			res := res
+			merged[res.Package] = &res
+			order = append(order, res.Package)
+			continue
 		}
+		for _, v := range res.Versions {
+			if !slices.Contains(existing.Versions, v) {
+				existing.Versions = append(existing.Versions, v)
+			}
+		}
+		if existing.Kind == "" {
+			existing.Kind = res.Kind
+		}
+		if !strings.Contains(existing.Source, res.Source) {
+			existing.Source += ", " + res.Source
+		}
+	}
+
+	out := []Result{}
+	for _, key := range order {
+		res := merged[key]
+		sortVersions(res.Versions)
+		out = append(out, *res)
+	}
+	return out
+}
+
+// Order results by relevance of package name to query, then by name
+func rank(results []Result, query string) []Result {
+	sort.SliceStable(results, func(i, j int) bool {
+		si, sj := score(results[i].Package, query), score(results[j].Package, query)
+		if si != sj {
+			return si < sj
+		}
+		return results[i].Package < results[j].Package
+	})
+	return results
+}
+
+// Lower score is more relevant: exact name, name prefix, name part, path part
+func score(pkg string, query string) int {
+	base := pkg[strings.LastIndex(pkg, "/")+1:]
+	switch {
+	case base == query:
+		return 0
+	case strings.HasPrefix(base, query):
+		return 1
+	case strings.Contains(base, query):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Sort versions from newest, semantic versions first
+func sortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := semver.NewVersion(versions[i])
+		vj, errj := semver.NewVersion(versions[j])
+		switch {
+		case erri == nil && errj == nil:
+			return vi.GreaterThan(vj)
+		case erri == nil:
+			return true
+		case errj == nil:
+			return false
+		}
+		return versions[i] > versions[j]
+	})
+}
 
+// Registry host and path of server URL
+func splitServer(server string) (string, string) {
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(server, "/"), ""
 	}
-	return nil, err
+	return u.Host, strings.Trim(u.Path, "/")
 }
@@ -0,0 +1,233 @@
+package xpkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/web-seven/overlock/internal/build"
+	"github.com/web-seven/overlock/internal/image"
+	"github.com/web-seven/overlock/internal/loader"
+	"github.com/web-seven/overlock/internal/packages"
+)
+
+const (
+	examplesDir                  = "examples"
+	examplesFileMode fs.FileMode = 0o644
+)
+
+// Build package images from linted directory, one for every target platform:
+// runtime image for kinds which run binary, package layer annotated as base
+// and examples layer when examples exist
+func (k *kind) Build(ctx context.Context, opts BuildOptions, logger *zap.SugaredLogger) (regv1.ImageIndex, error) {
+	diagnostics, err := k.Lint(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range diagnostics {
+		logger.Info(d)
+	}
+	if HasErrors(diagnostics) {
+		return nil, fmt.Errorf("%s package %s has lint errors", k.name, opts.Path)
+	}
+
+	cfg, err := build.LoadConfig(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Merge(opts.Build)
+	platforms := []*regv1.Platform{nil}
+	if k.binary != "" && opts.RuntimeImage == "" {
+		platforms, err = cfg.TargetPlatforms()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debugf("Loading %s package...", k.name)
+	packageLayer, err := image.LoadPackageLayerDirectory(ctx, nil, filepath.Join(opts.Path, k.packageDir), k.metaKinds)
+	if err != nil {
+		return nil, err
+	}
+	examplesLayer, err := k.examplesLayer(opts, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []mutate.IndexAddendum{}
+	for _, platform := range platforms {
+		img, err := k.runtimeImage(ctx, opts, cfg, platform, logger)
+		if err != nil {
+			return nil, err
+		}
+		img, err = image.AppendAnnotatedLayer(img, packageLayer, packages.BaseAnnotation)
+		if err != nil {
+			return nil, err
+		}
+		if examplesLayer != nil {
+			img, err = image.AppendAnnotatedLayer(img, examplesLayer, packages.ExamplesAnnotation)
+			if err != nil {
+				return nil, err
+			}
+		}
+		manifests = append(manifests, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: regv1.Descriptor{Platform: platform},
+		})
+	}
+	return mutate.AppendManifests(empty.Index, manifests...), nil
+}
+
+// Image of package built for single platform, nil for multi-platform package
+func SingleImage(index regv1.ImageIndex) (regv1.Image, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) != 1 {
+		return nil, nil
+	}
+	return index.Image(manifest.Manifests[0].Digest)
+}
+
+// Layer of examples, nil when package has no examples
+func (k *kind) examplesLayer(opts BuildOptions, logger *zap.SugaredLogger) (regv1.Layer, error) {
+	examplesPath := opts.ExamplesPath
+	if examplesPath == "" {
+		examplesPath = filepath.Join(opts.Path, examplesDir)
+		if _, err := os.Stat(examplesPath); os.IsNotExist(err) {
+			return nil, nil
+		}
+	}
+	logger.Debugf("Loading examples from %s", examplesPath)
+	layer, err := loadExamplesLayer(examplesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load examples")
+	}
+	return layer, nil
+}
+
+// Base image of package for platform, empty for kinds without binary
+func (k *kind) runtimeImage(ctx context.Context, opts BuildOptions, cfg *build.Config, platform *regv1.Platform, logger *zap.SugaredLogger) (regv1.Image, error) {
+	if k.binary == "" {
+		if opts.RuntimeImage != "" {
+			return nil, fmt.Errorf("%s package has no runtime image", k.name)
+		}
+		return empty.Image, nil
+	}
+	if opts.RuntimeImage != "" {
+		logger.Debugf("Embedding runtime image %s", opts.RuntimeImage)
+		return loadRuntimeImage(ctx, opts.RuntimeImage)
+	}
+
+	mainPath := opts.MainPath
+	if mainPath == "" {
+		mainPath = k.mainPath
+	}
+	if platform != nil {
+		logger.Debugf("Building %s for %s...", k.name, platform)
+	} else {
+		logger.Debugf("Building %s...", k.name)
+	}
+	content, err := cfg.Binary(ctx, filepath.Join(opts.Path, mainPath), k.binary, platform)
+	if err != nil {
+		return nil, err
+	}
+	binaryLayer, err := image.LoadBinaryLayer(content, k.binary, binaryFileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	cfgFile, err := empty.Image.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfgFile = cfgFile.DeepCopy()
+	if platform != nil {
+		cfgFile.OS = platform.OS
+		cfgFile.Architecture = platform.Architecture
+		cfgFile.Variant = platform.Variant
+	}
+	cfgFile.Config.WorkingDir = "/"
+	cfgFile.Config.ArgsEscaped = true
+	cfgFile.Config.Entrypoint = []string{"/" + k.binary}
+	cfgFile.Config.ExposedPorts = map[string]struct{}{
+		"9443": {},
+	}
+	img, err := mutate.ConfigFile(empty.Image, cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.AppendLayers(img, binaryLayer)
+}
+
+// Runtime image from image source, like docker-daemon://, or from registry
+func loadRuntimeImage(ctx context.Context, source string) (regv1.Image, error) {
+	_, statErr := os.Stat(source)
+	if statErr != nil && !strings.Contains(source, "://") {
+		return crane.Pull(source, crane.WithContext(ctx))
+	}
+	index, cleanup, err := loader.LoadSource(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	if index == nil {
+		return nil, fmt.Errorf("%s is not an image archive", source)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) != 1 {
+		return nil, fmt.Errorf("runtime image %s must contain exactly one image, found %d", source, len(manifest.Manifests))
+	}
+	img, err := index.Image(manifest.Manifests[0].Digest)
+	if err != nil {
+		return nil, err
+	}
+	// Image of extracted archive is read lazily, keep it in memory after cleanup
+	buf := new(bytes.Buffer)
+	if err := tarball.Write(nil, img, buf); err != nil {
+		return nil, err
+	}
+	return tarball.Image(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	}, nil)
+}
+
+// Layer with YAML documents of examples directory joined to examples file
+func loadExamplesLayer(path string) (regv1.Layer, error) {
+	docs := [][]byte{}
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(file) != ".yaml" {
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return image.LoadBinaryLayer(bytes.Join(docs, []byte("\n---\n")), packages.ExamplesFile, examplesFileMode)
+}
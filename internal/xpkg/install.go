@@ -0,0 +1,173 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/engine"
+)
+
+// Install package, existing package with the same repository is updated
+func (k *kind) Install(ctx context.Context, config *rest.Config, ref string, opts InstallOptions, logger *zap.SugaredLogger) error {
+	_, err := engine.VerifyApi(ctx, config, k.apiName())
+	if err != nil {
+		logger.Debug(err)
+		return fmt.Errorf("crossplane not installed in current context, %s not installed", k.name)
+	}
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	objName, source, err := objectName(ref)
+	if err != nil {
+		return err
+	}
+
+	resource := dc.Resource(k.gvr())
+	existing, err := resource.Get(ctx, objName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiGroup + "/" + k.version,
+				"kind":       k.name,
+				"metadata": map[string]interface{}{
+					"name": objName,
+				},
+				"spec": map[string]interface{}{
+					"package":                  source,
+					"skipDependencyResolution": opts.SkipDependencyResolution,
+				},
+			},
+		}
+		_, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to install %s %s", k.name, ref)
+		}
+		logger.Infof("%s %s installed.", k.name, source)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := unstructured.SetNestedField(existing.Object, source, "spec", "package"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(existing.Object, opts.SkipDependencyResolution, "spec", "skipDependencyResolution"); err != nil {
+		return err
+	}
+	// Revision of installed source is activated again after rollback
+	if err := unstructured.SetNestedField(existing.Object, automaticActivationPolicy, "spec", "revisionActivationPolicy"); err != nil {
+		return err
+	}
+	_, err = resource.Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s %s", k.name, ref)
+	}
+	logger.Infof("%s %s updated.", k.name, source)
+	return nil
+}
+
+// List installed packages with their health
+func (k *kind) List(ctx context.Context, dc dynamic.Interface) ([]Installed, error) {
+	list, err := dc.Resource(k.gvr()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	installed := []Installed{}
+	for _, item := range list.Items {
+		source, _, _ := unstructured.NestedString(item.Object, "spec", "package")
+		installed = append(installed, Installed{
+			Kind:      k.name,
+			Name:      item.GetName(),
+			Package:   source,
+			Installed: conditionTrue(item, "Installed"),
+			Healthy:   conditionTrue(item, "Healthy"),
+		})
+	}
+	return installed, nil
+}
+
+// Delete package by object name or reference
+func (k *kind) Delete(ctx context.Context, dc dynamic.Interface, ref string, logger *zap.SugaredLogger) error {
+	objName := k.resolveName(ctx, dc, ref)
+	err := dc.Resource(k.gvr()).Delete(ctx, objName, metav1.DeleteOptions{})
+	if kerrors.IsNotFound(err) {
+		return fmt.Errorf("%s %s not found", k.name, ref)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Infof("%s %s deleted.", k.name, objName)
+	return nil
+}
+
+// Object name of installed package by object name or reference
+func (k *kind) Name(ctx context.Context, dc dynamic.Interface, ref string) (string, error) {
+	objName := k.resolveName(ctx, dc, ref)
+	_, err := dc.Resource(k.gvr()).Get(ctx, objName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return "", fmt.Errorf("%s %s not found", k.name, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	return objName, nil
+}
+
+// Object name of package by object name or reference, not checking if it is
+// installed. Packages installed under other names, like by Crossplane Helm
+// chart, are found by source.
+func (k *kind) resolveName(ctx context.Context, dc dynamic.Interface, ref string) string {
+	resource := dc.Resource(k.gvr())
+	if _, err := resource.Get(ctx, ref, metav1.GetOptions{}); err == nil {
+		return ref
+	}
+	n, source, err := objectName(ref)
+	if err != nil {
+		return ref
+	}
+	if _, err := resource.Get(ctx, n, metav1.GetOptions{}); err == nil {
+		return n
+	}
+	if installed, err := k.List(ctx, dc); err == nil {
+		for _, pkg := range installed {
+			if pkg.Package == ref || pkg.Package == source {
+				return pkg.Name
+			}
+		}
+	}
+	return n
+}
+
+// Object name and source of package reference, named like Crossplane CLI does
+func objectName(ref string) (string, string, error) {
+	parsed, err := name.ParseReference(ref, name.WithDefaultRegistry(""))
+	if err != nil {
+		return "", "", errors.Wrapf(err, "package reference %s is not valid", ref)
+	}
+	return engine.ToDNSLabel(parsed.Context().RepositoryStr()), parsed.String(), nil
+}
+
+func conditionTrue(obj unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,346 @@
+package xpkg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	xv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/google/go-containerregistry/pkg/name"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/web-seven/overlock/internal/build"
+	"github.com/web-seven/overlock/internal/engine"
+	"github.com/web-seven/overlock/internal/packages"
+)
+
+// Severities of lint diagnostics
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem found in package file
+type Diagnostic struct {
+	File     string
+	Line     int
+	Severity string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// Check if diagnostics have errors
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// YAML document of package file with line where it starts
+type document struct {
+	file    string
+	line    int
+	content []byte
+	kind    string
+	name    string
+}
+
+type linter struct {
+	kind        *kind
+	path        string
+	docs        []*document
+	diagnostics []Diagnostic
+}
+
+// Lint package directory: metadata, XRD schemas, references of Compositions
+// to XRDs and functions, duplicate CRDs and dependencies
+func (k *kind) Lint(path string) ([]Diagnostic, error) {
+	packagePath := filepath.Join(path, k.packageDir)
+	l := &linter{kind: k, path: packagePath}
+	err := filepath.WalkDir(packagePath, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == examplesDir && file != packagePath {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || filepath.Ext(file) != ".yaml" || d.Name() == build.ProjectFile {
+			return nil
+		}
+		return l.readFile(file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.lintMeta()
+	l.lintDefinitions()
+	l.lintCompositions()
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i], l.diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.diagnostics, nil
+}
+
+func (l *linter) report(doc *document, line int, severity string, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		File:     doc.file,
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Read documents of file, reporting documents which could not be parsed or
+// are not included in package
+func (l *linter) readFile(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	for _, doc := range splitDocuments(file, content) {
+		res := &metav1.PartialObjectMetadata{}
+		if err := yaml.Unmarshal(doc.content, res); err != nil {
+			l.report(doc, doc.line, SeverityError, "invalid YAML: %v", err)
+			continue
+		}
+		doc.kind, doc.name = res.Kind, res.GetName()
+		if packages.IsMetaDocument(doc.content) {
+			l.docs = append(l.docs, doc)
+			continue
+		}
+		if !slices.Contains(l.kind.metaKinds, doc.kind) {
+			l.report(doc, doc.line, SeverityWarning, "%s %s is not included in %s package", doc.kind, doc.name, l.kind.name)
+			continue
+		}
+		l.docs = append(l.docs, doc)
+	}
+	return nil
+}
+
+// Package metadata and its dependencies
+func (l *linter) lintMeta() {
+	metas := []*document{}
+	for _, doc := range l.docs {
+		if packages.IsMetaDocument(doc.content) {
+			metas = append(metas, doc)
+		}
+	}
+	if len(metas) == 0 {
+		l.diagnostics = append(l.diagnostics, Diagnostic{
+			File:     l.path,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("no %s metadata found", l.kind.name),
+		})
+		return
+	}
+	for _, doc := range metas[1:] {
+		l.report(doc, doc.line, SeverityError, "package must have exactly one metadata document")
+	}
+
+	doc := metas[0]
+	if doc.kind != l.kind.name {
+		l.report(doc, lineOf(doc, "kind:"), SeverityError, "metadata of kind %s found in %s package", doc.kind, l.kind.name)
+	}
+	if doc.name == "" {
+		l.report(doc, lineOf(doc, "metadata:"), SeverityError, "metadata name is empty")
+	}
+	meta := &packages.Meta{}
+	if err := yaml.Unmarshal(doc.content, meta); err != nil {
+		l.report(doc, doc.line, SeverityError, "invalid metadata: %v", err)
+		return
+	}
+	for _, dep := range meta.Spec.DependsOn {
+		pkgs := []string{}
+		for _, pkg := range []*string{dep.Configuration, dep.Provider, dep.Function} {
+			if pkg != nil {
+				pkgs = append(pkgs, *pkg)
+			}
+		}
+		if len(pkgs) != 1 {
+			l.report(doc, lineOf(doc, "dependsOn:"), SeverityError, "dependency must set exactly one of configuration, provider or function")
+			continue
+		}
+		line := lineOf(doc, pkgs[0])
+		if _, err := name.NewRepository(pkgs[0]); err != nil {
+			l.report(doc, line, SeverityError, "dependency %s is not valid repository: %v", pkgs[0], err)
+		}
+		if dep.Version == "" {
+			l.report(doc, line, SeverityError, "dependency %s has no version", pkgs[0])
+		} else if !strings.HasPrefix(dep.Version, "sha256:") {
+			if _, err := semver.NewConstraint(dep.Version); err != nil {
+				l.report(doc, line, SeverityError, "dependency %s version %s is not valid constraint", pkgs[0], dep.Version)
+			}
+		}
+	}
+}
+
+// XRD schemas and duplicates of CRDs and XRDs
+func (l *linter) lintDefinitions() {
+	defined := map[string]*document{}
+	for _, doc := range l.docs {
+		if doc.kind != xv1.CompositeResourceDefinitionKind && doc.kind != "CustomResourceDefinition" {
+			continue
+		}
+		if first, ok := defined[doc.name]; ok {
+			l.report(doc, doc.line, SeverityError, "%s %s already defined in %s:%d", doc.kind, doc.name, first.file, first.line)
+			continue
+		}
+		defined[doc.name] = doc
+		if doc.kind == xv1.CompositeResourceDefinitionKind {
+			l.lintXRD(doc)
+		}
+	}
+}
+
+func (l *linter) lintXRD(doc *document) {
+	xrd := &xv1.CompositeResourceDefinition{}
+	if err := yaml.Unmarshal(doc.content, xrd); err != nil {
+		l.report(doc, doc.line, SeverityError, "invalid CompositeResourceDefinition: %v", err)
+		return
+	}
+	if xrd.Spec.Group == "" || xrd.Spec.Names.Kind == "" {
+		l.report(doc, lineOf(doc, "spec:"), SeverityError, "group and names.kind are required")
+	}
+	referenceable := 0
+	for _, v := range xrd.Spec.Versions {
+		line := lineOf(doc, "name: "+v.Name)
+		if v.Referenceable {
+			referenceable++
+		}
+		if v.Schema == nil || len(v.Schema.OpenAPIV3Schema.Raw) == 0 {
+			l.report(doc, line, SeverityError, "version %s has no openAPIV3Schema", v.Name)
+			continue
+		}
+		schema := &apiextensionsv1.JSONSchemaProps{}
+		if err := yaml.UnmarshalStrict(v.Schema.OpenAPIV3Schema.Raw, schema); err != nil {
+			l.report(doc, line, SeverityError, "version %s has invalid openAPIV3Schema: %v", v.Name, err)
+			continue
+		}
+		if schema.Type != "object" {
+			l.report(doc, line, SeverityError, "openAPIV3Schema of version %s must be of type object", v.Name)
+		}
+	}
+	if referenceable != 1 {
+		l.report(doc, lineOf(doc, "versions:"), SeverityError, "exactly one version must be referenceable, found %d", referenceable)
+	}
+}
+
+// References of Compositions to XRDs of package and to functions of
+// dependencies
+func (l *linter) lintCompositions() {
+	types := map[string]bool{}
+	functions := map[string]bool{}
+	for _, doc := range l.docs {
+		switch {
+		case doc.kind == xv1.CompositeResourceDefinitionKind:
+			xrd := &xv1.CompositeResourceDefinition{}
+			if err := yaml.Unmarshal(doc.content, xrd); err != nil {
+				continue
+			}
+			for _, v := range xrd.Spec.Versions {
+				types[xrd.Spec.Group+"/"+v.Name+"/"+xrd.Spec.Names.Kind] = true
+			}
+		case packages.IsMetaDocument(doc.content):
+			meta := &packages.Meta{}
+			if err := yaml.Unmarshal(doc.content, meta); err != nil {
+				continue
+			}
+			for _, dep := range meta.Spec.DependsOn {
+				if dep.Function == nil {
+					continue
+				}
+				repo, err := name.NewRepository(*dep.Function)
+				if err != nil {
+					continue
+				}
+				functions[engine.ToDNSLabel(repo.RepositoryStr())] = true
+				functions[filepath.Base(repo.RepositoryStr())] = true
+			}
+		}
+	}
+
+	for _, doc := range l.docs {
+		if doc.kind != xv1.CompositionKind {
+			continue
+		}
+		comp := &xv1.Composition{}
+		if err := yaml.Unmarshal(doc.content, comp); err != nil {
+			l.report(doc, doc.line, SeverityError, "invalid Composition: %v", err)
+			continue
+		}
+		ref := comp.Spec.CompositeTypeRef
+		if !types[ref.APIVersion+"/"+ref.Kind] {
+			l.report(doc, lineOf(doc, "compositeTypeRef:"), SeverityWarning, "composite type %s %s is not defined in package", ref.APIVersion, ref.Kind)
+		}
+		for _, step := range comp.Spec.Pipeline {
+			if !functions[step.FunctionRef.Name] {
+				l.report(doc, lineOf(doc, "name: "+step.FunctionRef.Name), SeverityWarning, "function %s of step %s is not in dependencies", step.FunctionRef.Name, step.Step)
+			}
+		}
+	}
+}
+
+// Documents of YAML stream with lines where they start
+func splitDocuments(file string, content []byte) []*document {
+	docs := []*document{}
+	current := &document{file: file, line: 1}
+	buf := &bytes.Buffer{}
+	flush := func(next int) {
+		if len(bytes.TrimSpace(buf.Bytes())) > 0 {
+			current.content = append([]byte{}, buf.Bytes()...)
+			docs = append(docs, current)
+		}
+		buf.Reset()
+		current = &document{file: file, line: next}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if strings.HasPrefix(text, "---") && strings.TrimSpace(strings.TrimPrefix(text, "---")) == "" {
+			flush(line + 1)
+			continue
+		}
+		if buf.Len() == 0 && strings.TrimSpace(text) == "" {
+			current.line = line + 1
+			continue
+		}
+		buf.WriteString(text)
+		buf.WriteByte('\n')
+	}
+	flush(line + 1)
+	return docs
+}
+
+// Line of file with first occurrence of text in document, or document start
+func lineOf(doc *document, text string) int {
+	for i, line := range strings.Split(string(doc.content), "\n") {
+		if strings.Contains(line, text) {
+			return doc.line + i
+		}
+	}
+	return doc.line
+}
@@ -0,0 +1,286 @@
+package xpkg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lines   []int
+	}{
+		{
+			name:    "single document",
+			content: "kind: Configuration\nmetadata:\n  name: example\n",
+			lines:   []int{1},
+		},
+		{
+			name:    "separated documents",
+			content: "kind: A\n---\nkind: B\n---\nkind: C\n",
+			lines:   []int{1, 3, 5},
+		},
+		{
+			name:    "leading separator and blank lines",
+			content: "---\n\n\nkind: A\n---\n",
+			lines:   []int{4},
+		},
+		{
+			name:    "empty documents",
+			content: "---\n---\n\n---\nkind: A\n",
+			lines:   []int{5},
+		},
+		{
+			name:    "separator with trailing spaces",
+			content: "kind: A\n---  \nkind: B\n",
+			lines:   []int{1, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := []int{}
+			for _, doc := range splitDocuments("package.yaml", []byte(tt.content)) {
+				lines = append(lines, doc.line)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("Expected documents at lines %v, got %v", tt.lines, lines)
+			}
+		})
+	}
+}
+
+func TestLineOf(t *testing.T) {
+	doc := &document{file: "package.yaml", line: 5, content: []byte("kind: A\nspec:\n  versions:\n  - name: v1\n")}
+	tests := []struct {
+		text string
+		line int
+	}{
+		{text: "kind:", line: 5},
+		{text: "versions:", line: 7},
+		{text: "name: v1", line: 8},
+		{text: "missing", line: 5},
+	}
+	for _, tt := range tests {
+		if line := lineOf(doc, tt.text); line != tt.line {
+			t.Errorf("Expected line %d of %q, got %d", tt.line, tt.text, line)
+		}
+	}
+}
+
+const (
+	configurationMeta = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: example
+`
+	validXRD = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+`
+	pipelineComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xexamples.example.org
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XExample
+  mode: Pipeline
+  pipeline:
+  - step: patch-and-transform
+    functionRef:
+      name: crossplane-contrib-function-patch-and-transform
+`
+	functionDependency = `spec:
+  dependsOn:
+  - function: xpkg.upbound.io/crossplane-contrib/function-patch-and-transform
+    version: ">=v0.1.0"
+`
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		files       map[string]string
+		diagnostics []string
+	}{
+		{
+			name:  "valid configuration",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "xrd.yaml": validXRD},
+		},
+		{
+			name:        "no metadata",
+			kind:        "configuration",
+			files:       map[string]string{"xrd.yaml": validXRD},
+			diagnostics: []string{"{dir}:0: error: no Configuration metadata found"},
+		},
+		{
+			name:  "metadata of other kind",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": "apiVersion: meta.pkg.crossplane.io/v1\nkind: Provider\nmetadata:\n  name: example\n"},
+			diagnostics: []string{
+				"{dir}/crossplane.yaml:2: error: metadata of kind Provider found in Configuration package",
+			},
+		},
+		{
+			name:  "duplicate metadata",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta + "---\n" + configurationMeta},
+			diagnostics: []string{
+				"{dir}/crossplane.yaml:6: error: package must have exactly one metadata document",
+			},
+		},
+		{
+			name: "invalid dependencies",
+			kind: "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta + `spec:
+  dependsOn:
+  - provider: xpkg.upbound.io/upbound/provider-aws-s3
+  - function: xpkg.upbound.io/crossplane-contrib/function-patch-and-transform
+    version: "not a constraint"
+`},
+			diagnostics: []string{
+				"{dir}/crossplane.yaml:7: error: dependency xpkg.upbound.io/upbound/provider-aws-s3 has no version",
+				"{dir}/crossplane.yaml:8: error: dependency xpkg.upbound.io/crossplane-contrib/function-patch-and-transform version not a constraint is not valid constraint",
+			},
+		},
+		{
+			name: "XRD without schema and referenceable version",
+			kind: "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "xrd.yaml": `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xexamples.example.org
+spec:
+  group: example.org
+  names:
+    kind: XExample
+    plural: xexamples
+  versions:
+  - name: v1alpha1
+    served: true
+`},
+			diagnostics: []string{
+				"{dir}/xrd.yaml:10: error: exactly one version must be referenceable, found 0",
+				"{dir}/xrd.yaml:11: error: version v1alpha1 has no openAPIV3Schema",
+			},
+		},
+		{
+			name:  "XRD schema not object",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "xrd.yaml": validXRD[:len(validXRD)-len("type: object\n")] + "type: string\n"},
+			diagnostics: []string{
+				"{dir}/xrd.yaml:11: error: openAPIV3Schema of version v1alpha1 must be of type object",
+			},
+		},
+		{
+			name:  "duplicate XRD in second document",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "xrd.yaml": validXRD + "---\n" + validXRD},
+			diagnostics: []string{
+				"{dir}/xrd.yaml:18: error: CompositeResourceDefinition xexamples.example.org already defined in {dir}/xrd.yaml:1",
+			},
+		},
+		{
+			name:  "document not included in package",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "secret.yaml": "\napiVersion: v1\nkind: Secret\nmetadata:\n  name: example\n"},
+			diagnostics: []string{
+				"{dir}/secret.yaml:2: warning: Secret example is not included in Configuration package",
+			},
+		},
+		{
+			name: "valid composition",
+			kind: "configuration",
+			files: map[string]string{
+				"crossplane.yaml":  configurationMeta + functionDependency,
+				"xrd.yaml":         validXRD,
+				"composition.yaml": pipelineComposition,
+			},
+		},
+		{
+			name: "composite type not defined in package",
+			kind: "configuration",
+			files: map[string]string{
+				"crossplane.yaml":  configurationMeta + functionDependency,
+				"composition.yaml": pipelineComposition,
+			},
+			diagnostics: []string{
+				"{dir}/composition.yaml:6: warning: composite type example.org/v1alpha1 XExample is not defined in package",
+			},
+		},
+		{
+			name: "function not in dependencies",
+			kind: "configuration",
+			files: map[string]string{
+				"crossplane.yaml":  configurationMeta,
+				"xrd.yaml":         validXRD,
+				"composition.yaml": pipelineComposition,
+			},
+			diagnostics: []string{
+				"{dir}/composition.yaml:13: warning: function crossplane-contrib-function-patch-and-transform of step patch-and-transform is not in dependencies",
+			},
+		},
+		{
+			name:  "yml files are not read",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "broken.yml": "kind: [\n"},
+		},
+		{
+			name:  "invalid YAML",
+			kind:  "configuration",
+			files: map[string]string{"crossplane.yaml": configurationMeta, "broken.yaml": "kind: [\n"},
+			diagnostics: []string{
+				"{dir}/broken.yaml:1: error: invalid YAML: error converting YAML to JSON: yaml: line 1: did not find expected node content",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			pkg, err := New(tt.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diagnostics, err := pkg.Lint(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, d := range diagnostics {
+				got = append(got, d.String())
+			}
+			expected := []string{}
+			for _, d := range tt.diagnostics {
+				expected = append(expected, strings.ReplaceAll(d, "{dir}", dir))
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected diagnostics:\n%v\ngot:\n%v", expected, got)
+			}
+		})
+	}
+}
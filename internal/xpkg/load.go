@@ -0,0 +1,129 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/loader"
+	"github.com/web-seven/overlock/internal/packages"
+	"github.com/web-seven/overlock/pkg/registry"
+)
+
+const binaryFileMode os.FileMode = 0o777
+
+// Load package from archive, STDIN or directory to local registry
+func (k *kind) Load(ctx context.Context, config *rest.Config, dc dynamic.Interface, opts LoadOptions, logger *zap.SugaredLogger) (string, error) {
+	pkgName := opts.Name
+	if opts.Upgrade {
+		var err error
+		pkgName, err = k.upgradeVersion(ctx, dc, pkgName)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to upgrade version")
+		}
+		logger.Debugf("Upgraded version: %s", pkgName)
+	}
+
+	var (
+		img   regv1.Image
+		index regv1.ImageIndex
+		err   error
+	)
+	switch {
+	case opts.Archive != nil:
+		logger.Debug("Loading from archive stream")
+		img, err = readArchive(opts.Archive)
+	case opts.Path == "":
+		return "", fmt.Errorf("archive path or STDIN required for load %s", k.name)
+	default:
+		var fi os.FileInfo
+		fi, err = os.Stat(opts.Path)
+		if err != nil {
+			return "", err
+		}
+		if fi.IsDir() {
+			logger.Debugf("Loading from directory: %s", opts.Path)
+			index, err = k.Build(ctx, BuildOptions{Path: opts.Path, MainPath: opts.MainPath, Build: opts.Build}, logger)
+			if err == nil {
+				img, err = SingleImage(index)
+			}
+		} else {
+			logger.Debugf("Loading from file: %s", opts.Path)
+			img, err = loader.LoadPathArchive(opts.Path)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := ensureLocalRegistry(ctx, config, logger); err != nil {
+		return "", err
+	}
+	logger.Debug("Pushing to local registry")
+	pushOpts := []registry.PushOption{}
+	if opts.SignKey != "" {
+		pushOpts = append(pushOpts, registry.WithSignKey(opts.SignKey))
+	}
+	if img != nil {
+		err = registry.PushLocalRegistry(ctx, pkgName, img, config, logger, pushOpts...)
+	} else {
+		err = registry.PushLocalRegistryIndex(ctx, pkgName, index, config, logger, pushOpts...)
+	}
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("%s %s loaded to local registry.", k.name, pkgName)
+	return pkgName, nil
+}
+
+// Next patch version of installed package with the same minor version
+func (k *kind) upgradeVersion(ctx context.Context, dc dynamic.Interface, pkgName string) (string, error) {
+	installed, err := k.List(ctx, dc)
+	if err != nil {
+		return "", err
+	}
+	pkgs := []packages.Package{}
+	for _, pkg := range installed {
+		pkgs = append(pkgs, packages.Package{Name: pkg.Name, Url: pkg.Package})
+	}
+	p := packages.Package{}
+	return p.UpgradeVersion(ctx, dc, pkgName, pkgs)
+}
+
+// Read package archive from stream through temporary file
+func readArchive(stream io.Reader) (regv1.Image, error) {
+	tmpFile, err := os.CreateTemp("", "overlock-package-*")
+	if err != nil {
+		return nil, err
+	}
+	defer tmpFile.Close()
+	if _, err := io.Copy(tmpFile, stream); err != nil {
+		return nil, fmt.Errorf("failed to write to temp file: %w", err)
+	}
+	return loader.LoadPathArchive(tmpFile.Name())
+}
+
+// Create default local registry when it does not exist
+func ensureLocalRegistry(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	client, err := kube.Client(config)
+	if err != nil {
+		return err
+	}
+	isLocal, err := registry.IsLocalRegistry(ctx, client)
+	if isLocal && err == nil {
+		return nil
+	}
+	if err != nil {
+		logger.Debug(err)
+	}
+	reg := registry.NewLocal()
+	reg.SetDefault(true)
+	return reg.Create(ctx, config, logger)
+}
@@ -0,0 +1,154 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	packageLabel              = "pkg.crossplane.io/package"
+	activeDesiredState        = "Active"
+	inactiveDesiredState      = "Inactive"
+	manualActivationPolicy    = "Manual"
+	automaticActivationPolicy = "Automatic"
+)
+
+// Image of active revision of installed package, by object name or reference
+func (k *kind) ActiveImage(ctx context.Context, dc dynamic.Interface, ref string) (string, error) {
+	objName := k.resolveName(ctx, dc, ref)
+	revisions, err := k.revisionList(ctx, dc, objName)
+	if err != nil {
+		return "", err
+	}
+	for _, rev := range revisions {
+		state, _, _ := unstructured.NestedString(rev.Object, "spec", "desiredState")
+		if state != activeDesiredState {
+			continue
+		}
+		image, _, _ := unstructured.NestedString(rev.Object, "spec", "image")
+		return image, nil
+	}
+	return "", fmt.Errorf("no active revision of %s %s found", k.name, ref)
+}
+
+// Revisions of installed package ordered by revision number
+func (k *kind) Revisions(ctx context.Context, dc dynamic.Interface, ref string) ([]Revision, error) {
+	objName, err := k.Name(ctx, dc, ref)
+	if err != nil {
+		return nil, err
+	}
+	list, err := k.revisionList(ctx, dc, objName)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []Revision{}
+	for _, rev := range list {
+		number, _, _ := unstructured.NestedInt64(rev.Object, "spec", "revision")
+		image, _, _ := unstructured.NestedString(rev.Object, "spec", "image")
+		state, _, _ := unstructured.NestedString(rev.Object, "spec", "desiredState")
+		revisions = append(revisions, Revision{
+			Name:    rev.GetName(),
+			Number:  number,
+			Image:   image,
+			Active:  state == activeDesiredState,
+			Healthy: conditionTrue(rev, "Healthy"),
+			Created: rev.GetCreationTimestamp().Time,
+		})
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Number < revisions[j].Number
+	})
+	return revisions, nil
+}
+
+// Switch installed package to manual activation and activate revision with
+// number, revision preceding active one if number is 0
+func (k *kind) Rollback(ctx context.Context, dc dynamic.Interface, ref string, number int64, logger *zap.SugaredLogger) (*Revision, error) {
+	revisions, err := k.Revisions(ctx, dc, ref)
+	if err != nil {
+		return nil, err
+	}
+	target := -1
+	for i, rev := range revisions {
+		if number == 0 && rev.Active {
+			target = i - 1
+			break
+		}
+		if number != 0 && rev.Number == number {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		if number == 0 {
+			return nil, fmt.Errorf("no revision of %s %s before active one, older revisions are kept up to revisionHistoryLimit", k.name, ref)
+		}
+		return nil, fmt.Errorf("revision %d of %s %s not found", number, k.name, ref)
+	}
+	if revisions[target].Active {
+		return nil, fmt.Errorf("revision %d of %s %s is already active", revisions[target].Number, k.name, ref)
+	}
+
+	objName, err := k.Name(ctx, dc, ref)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := dc.Resource(k.gvr()).Get(ctx, objName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	policy, _, _ := unstructured.NestedString(obj.Object, "spec", "revisionActivationPolicy")
+	if policy != manualActivationPolicy {
+		if err := unstructured.SetNestedField(obj.Object, manualActivationPolicy, "spec", "revisionActivationPolicy"); err != nil {
+			return nil, err
+		}
+		if _, err := dc.Resource(k.gvr()).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+			return nil, errors.Wrapf(err, "failed to set manual activation of %s %s", k.name, objName)
+		}
+		logger.Infof("%s %s switched to manual revision activation.", k.name, objName)
+	}
+
+	for _, rev := range revisions {
+		if rev.Active {
+			if err := k.setDesiredState(ctx, dc, rev.Name, inactiveDesiredState); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := k.setDesiredState(ctx, dc, revisions[target].Name, activeDesiredState); err != nil {
+		return nil, err
+	}
+	logger.Infof("%s %s rolled back to revision %d (%s).", k.name, objName, revisions[target].Number, revisions[target].Image)
+	return &revisions[target], nil
+}
+
+func (k *kind) revisionList(ctx context.Context, dc dynamic.Interface, objName string) ([]unstructured.Unstructured, error) {
+	list, err := dc.Resource(k.revisionGVR()).List(ctx, metav1.ListOptions{
+		LabelSelector: packageLabel + "=" + objName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (k *kind) setDesiredState(ctx context.Context, dc dynamic.Interface, revision string, state string) error {
+	obj, err := dc.Resource(k.revisionGVR()).Get(ctx, revision, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, state, "spec", "desiredState"); err != nil {
+		return err
+	}
+	if _, err := dc.Resource(k.revisionGVR()).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to set %s revision %s %s", k.name, revision, state)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Name of runtime config referenced by installed package, empty if not set
+func (k *kind) RuntimeConfig(ctx context.Context, dc dynamic.Interface, ref string) (string, error) {
+	obj, err := k.runtimePackage(ctx, dc, ref)
+	if err != nil {
+		return "", err
+	}
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "runtimeConfigRef", "name")
+	return name, nil
+}
+
+// Reference runtime config from installed package
+func (k *kind) SetRuntimeConfig(ctx context.Context, dc dynamic.Interface, ref string, name string) error {
+	obj, err := k.runtimePackage(ctx, dc, ref)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, name, "spec", "runtimeConfigRef", "name"); err != nil {
+		return err
+	}
+	_, err = dc.Resource(k.gvr()).Update(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+// Installed package of kind which has runtime
+func (k *kind) runtimePackage(ctx context.Context, dc dynamic.Interface, ref string) (*unstructured.Unstructured, error) {
+	if k.binary == "" {
+		return nil, fmt.Errorf("%s has no runtime", k.name)
+	}
+	obj, err := dc.Resource(k.gvr()).Get(ctx, k.resolveName(ctx, dc, ref), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s %s not found", k.name, ref)
+	}
+	return obj, err
+}
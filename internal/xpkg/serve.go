@@ -0,0 +1,90 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rjeczalik/notify"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+// Serve package directory, loading and installing new patch version on every
+// change of YAML or Go files
+func (k *kind) Serve(ctx context.Context, config *rest.Config, dc dynamic.Interface, opts ServeOptions, logger *zap.SugaredLogger) error {
+	logger.Infof("Started serve path: %s", opts.Path)
+	k.loadServed(ctx, config, dc, opts, logger)
+
+	c := make(chan notify.EventInfo, 1)
+	if err := notify.Watch(filepath.Join(opts.Path, "..."), c, notify.Create, notify.Write, notify.Rename, notify.Remove); err != nil {
+		return err
+	}
+	defer notify.Stop(c)
+
+	for {
+		select {
+		case ev := <-c:
+			fileExt := filepath.Ext(ev.Path())
+			if fileExt == ".yaml" || fileExt == ".go" {
+				logger.Debugf("Changed file: %s", ev)
+				k.loadServed(ctx, config, dc, opts, logger)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (k *kind) loadServed(ctx context.Context, config *rest.Config, dc dynamic.Interface, opts ServeOptions, logger *zap.SugaredLogger) {
+	metaName, err := k.metaName(filepath.Join(opts.Path, k.packageDir))
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+
+	logger.Infof("Changes detected, apply %s: %s", k.name, metaName)
+	ref, err := k.Load(ctx, config, dc, LoadOptions{
+		Name:     fmt.Sprintf("%s:0.0.0", metaName),
+		Path:     opts.Path,
+		Upgrade:  true,
+		MainPath: opts.MainPath,
+		Build:    opts.Build,
+	}, logger)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	if err := k.Install(ctx, config, ref, InstallOptions{}, logger); err != nil {
+		logger.Error(err)
+	}
+}
+
+// Name from package metadata of kind in package directory
+func (k *kind) metaName(packagePath string) (string, error) {
+	files, err := os.ReadDir(packagePath)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range files {
+		if !e.Type().IsRegular() || filepath.Ext(e.Name()) != ".yaml" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(packagePath, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		res := &metav1.PartialObjectMetadata{}
+		if err := yaml.Unmarshal(content, res); err != nil {
+			continue
+		}
+		if res.Kind == k.name && res.GetName() != "" {
+			return res.GetName(), nil
+		}
+	}
+	return "", fmt.Errorf("no %s metadata found in %s", k.name, packagePath)
+}
@@ -0,0 +1,99 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/packages"
+)
+
+// Installed package with newest version of its repository
+type Update struct {
+	Installed
+	Repository string
+	Current    string
+	// Newest version satisfying constraint, empty if it was not resolved
+	Latest string
+	// Reason why newest version was not resolved
+	Err error
+}
+
+// Newer version satisfying constraint exists
+func (u Update) Available() bool {
+	if u.Latest == "" {
+		return false
+	}
+	latest, err := semver.NewVersion(u.Latest)
+	if err != nil {
+		return false
+	}
+	current, err := semver.NewVersion(u.Current)
+	if err != nil {
+		return false
+	}
+	return latest.GreaterThan(current)
+}
+
+// Reference of newest version of package
+func (u Update) Reference() string {
+	return u.Repository + ":" + u.Latest
+}
+
+// Resolve newest versions of installed packages satisfying constraint from
+// tags of their remote repositories, latest version if constraint is empty.
+// Packages pinned to digests or to non semantic versions are not resolved.
+func Updates(installed []Installed, constraint string, opts ...crane.Option) []Update {
+	updates := []Update{}
+	for _, pkg := range installed {
+		repo, version := packages.SplitReference(pkg.Package)
+		update := Update{Installed: pkg, Repository: repo, Current: version}
+		switch {
+		case strings.HasPrefix(version, "sha256:"):
+			update.Err = fmt.Errorf("pinned to digest")
+		case version == "":
+			update.Err = fmt.Errorf("no version")
+		default:
+			if _, err := semver.NewVersion(version); err != nil {
+				update.Err = fmt.Errorf("version %s is not semantic version", version)
+				break
+			}
+			update.Latest, update.Err = packages.ResolveVersion(repo, constraint, opts...)
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// Change source of installed package to reference, restoring automatic
+// activation of its revisions
+func (k *kind) Upgrade(ctx context.Context, dc dynamic.Interface, name string, ref string, logger *zap.SugaredLogger) error {
+	resource := dc.Resource(k.gvr())
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return fmt.Errorf("%s %s not found", k.name, name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, ref, "spec", "package"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, automaticActivationPolicy, "spec", "revisionActivationPolicy"); err != nil {
+		return err
+	}
+	if _, err := resource.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to upgrade %s %s", k.name, name)
+	}
+	logger.Infof("%s %s upgraded to %s.", k.name, name, ref)
+	return nil
+}
@@ -0,0 +1,158 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/web-seven/overlock/internal/kube"
+	overlockerrors "github.com/web-seven/overlock/pkg/errors"
+)
+
+// Wait until packages of references or object names are installed and healthy
+// with revision of requested source, without limit if timeout is 0. Source of
+// package waited by object name is its spec.package. Condition transitions of
+// packages and failures of their revisions, like unresolved dependencies, are
+// reported while waiting.
+func (k *kind) WaitHealthy(ctx context.Context, dc dynamic.Interface, refs []string, timeout time.Duration, logger *zap.SugaredLogger) error {
+	sources := map[string]string{}
+	healthy := map[string]bool{}
+	for _, ref := range refs {
+		objName := k.resolveName(ctx, dc, ref)
+		source := ""
+		if objName != ref {
+			var err error
+			if _, source, err = objectName(ref); err != nil {
+				return err
+			}
+		}
+		sources[objName] = source
+		healthy[objName] = false
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	watchCtx, stop := context.WithCancel(ctx)
+	revisionsDone, err := kube.DynamicWatch(watchCtx, dc.Resource(k.revisionGVR()), nil, k.reportRevisions(sources, logger))
+	if err != nil {
+		stop()
+		return err
+	}
+	defer func() {
+		stop()
+		<-revisionsDone
+	}()
+
+	conditions := map[string]string{}
+	for {
+		done, err := kube.DynamicWatch(watchCtx, dc.Resource(k.gvr()), nil, func(u *unstructured.Unstructured) (bool, error) {
+			if _, ok := healthy[u.GetName()]; !ok {
+				return false, nil
+			}
+			reportConditions(k.name+" "+u.GetName(), u, conditions, logger)
+			healthy[u.GetName()] = isCurrent(u, sources[u.GetName()]) && conditionTrue(*u, "Installed") && conditionTrue(*u, "Healthy")
+			for _, h := range healthy {
+				if !h {
+					return false, nil
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		err = <-done
+		if err == nil {
+			logger.Infof("%s(s) are healthy.", k.name)
+			return nil
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			pending := []string{}
+			for name, h := range healthy {
+				if !h {
+					pending = append(pending, name)
+				}
+			}
+			sort.Strings(pending)
+			timeoutErr := overlockerrors.NewTimeoutError(fmt.Sprintf("waiting for %s(s) to become healthy", k.name), timeout, pending)
+			timeoutErr.Err = ctx.Err()
+			return timeoutErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Watch was closed by API server, watch again
+		logger.Debugf("Watch of %s(s) stopped: %v", k.name, err)
+	}
+}
+
+// Report failures of revisions of awaited packages, like unresolved
+// dependencies
+func (k *kind) reportRevisions(packages map[string]string, logger *zap.SugaredLogger) func(u *unstructured.Unstructured) (bool, error) {
+	messages := map[string]string{}
+	return func(u *unstructured.Unstructured) (bool, error) {
+		pkg := u.GetLabels()[packageLabel]
+		if _, ok := packages[pkg]; !ok {
+			return false, nil
+		}
+		conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["status"] != "False" {
+				continue
+			}
+			message, _ := condition["message"].(string)
+			key := u.GetName() + "/" + fmt.Sprint(condition["type"])
+			if message == "" || messages[key] == message {
+				continue
+			}
+			messages[key] = message
+			logger.Infof("%s %s revision %s: %s", k.name, pkg, u.GetName(), message)
+		}
+		return false, nil
+	}
+}
+
+// Current revision of package is of source, of spec.package if source is
+// empty, so conditions of previous revision are not taken as healthy
+func isCurrent(u *unstructured.Unstructured, source string) bool {
+	if source == "" {
+		source, _, _ = unstructured.NestedString(u.Object, "spec", "package")
+	}
+	current, _, _ := unstructured.NestedString(u.Object, "status", "currentIdentifier")
+	if current == source {
+		return true
+	}
+	_, currentSource, err := objectName(current)
+	if err != nil {
+		return false
+	}
+	_, source, err = objectName(source)
+	return err == nil && currentSource == source
+}
+
+// Report conditions of object which changed since last report
+func reportConditions(object string, u *unstructured.Unstructured, reported map[string]string, logger *zap.SugaredLogger) {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType := fmt.Sprint(condition["type"])
+		state := fmt.Sprintf("%v (%v)", condition["status"], condition["reason"])
+		if reported[object+"/"+conditionType] == state {
+			continue
+		}
+		reported[object+"/"+conditionType] = state
+		logger.Infof("%s %s: %s", object, conditionType, state)
+	}
+}
@@ -0,0 +1,203 @@
+package xpkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/build"
+)
+
+const apiGroup = "pkg.crossplane.io"
+
+// Crossplane package kind, like Provider, managed with the same operations
+type Package interface {
+	// Name of package kind
+	Kind() string
+	// Install or update package from reference
+	Install(ctx context.Context, config *rest.Config, ref string, opts InstallOptions, logger *zap.SugaredLogger) error
+	// List packages of kind installed in cluster
+	List(ctx context.Context, dc dynamic.Interface) ([]Installed, error)
+	// Delete installed package by reference or object name
+	Delete(ctx context.Context, dc dynamic.Interface, ref string, logger *zap.SugaredLogger) error
+	// Validate package directory, diagnostics with errors prevent build
+	Lint(path string) ([]Diagnostic, error)
+	// Build package images of target platforms from package directory
+	Build(ctx context.Context, opts BuildOptions, logger *zap.SugaredLogger) (regv1.ImageIndex, error)
+	// Load package to local registry, returns reference of loaded package
+	Load(ctx context.Context, config *rest.Config, dc dynamic.Interface, opts LoadOptions, logger *zap.SugaredLogger) (string, error)
+	// Watch package directory, load and install it on changes
+	Serve(ctx context.Context, config *rest.Config, dc dynamic.Interface, opts ServeOptions, logger *zap.SugaredLogger) error
+	// Object name of installed package by object name or reference
+	Name(ctx context.Context, dc dynamic.Interface, ref string) (string, error)
+	// Name of runtime config referenced by installed package
+	RuntimeConfig(ctx context.Context, dc dynamic.Interface, ref string) (string, error)
+	// Reference runtime config from installed package
+	SetRuntimeConfig(ctx context.Context, dc dynamic.Interface, ref string, name string) error
+	// Image of active revision of installed package
+	ActiveImage(ctx context.Context, dc dynamic.Interface, name string) (string, error)
+	// Revisions of installed package ordered by revision number
+	Revisions(ctx context.Context, dc dynamic.Interface, ref string) ([]Revision, error)
+	// Activate revision of installed package, previous revision if number is 0
+	Rollback(ctx context.Context, dc dynamic.Interface, ref string, number int64, logger *zap.SugaredLogger) (*Revision, error)
+	// Change source of installed package to reference
+	Upgrade(ctx context.Context, dc dynamic.Interface, name string, ref string, logger *zap.SugaredLogger) error
+	// Wait until packages with references are healthy
+	WaitHealthy(ctx context.Context, dc dynamic.Interface, refs []string, timeout time.Duration, logger *zap.SugaredLogger) error
+}
+
+// Package installed in cluster
+type Installed struct {
+	Kind      string
+	Name      string
+	Package   string
+	Installed bool
+	Healthy   bool
+}
+
+// Revision of installed package
+type Revision struct {
+	Name    string
+	Number  int64
+	Image   string
+	Active  bool
+	Healthy bool
+	Created time.Time
+}
+
+// Options of package install
+type InstallOptions struct {
+	// Dependencies are not installed by Crossplane, used for pinned packages
+	SkipDependencyResolution bool
+}
+
+// Options of package load
+type LoadOptions struct {
+	// Reference of package in local registry, like provider-example:v0.1.0
+	Name string
+	// Package archive or package directory
+	Path string
+	// Package archive stream, like STDIN, read instead of path when set
+	Archive io.Reader
+	// Increase patch version of installed package with same minor version
+	Upgrade bool
+	// Private key to sign package in local registry
+	SignKey string
+	// Path of main module relative to package directory, for kinds with binary
+	MainPath string
+	// Build of binary, overrides project file of package directory
+	Build build.Config
+}
+
+// Options of package build
+type BuildOptions struct {
+	// Package directory
+	Path string
+	// Path of main module relative to package directory, for kinds with binary
+	MainPath string
+	// Directory of examples, examples directory of package if empty
+	ExamplesPath string
+	// Runtime image embedded into package instead of binary built from main module
+	RuntimeImage string
+	// Build of binary, overrides project file of package directory
+	Build build.Config
+}
+
+// Options of package serve
+type ServeOptions struct {
+	Path     string
+	MainPath string
+	Build    build.Config
+}
+
+// Package kind definition, new kinds are supported by adding it to kinds
+type kind struct {
+	name      string
+	version   string
+	resource  string
+	metaKinds []string
+	// Directory of package YAML files relative to package root
+	packageDir string
+	// Binary built from Go module and used as entrypoint of package image
+	binary   string
+	mainPath string
+}
+
+var kinds = map[string]*kind{
+	"configuration": {
+		name:      "Configuration",
+		version:   "v1",
+		resource:  "configurations",
+		metaKinds: []string{"Configuration", "CompositeResourceDefinition", "Composition"},
+	},
+	"provider": {
+		name:       "Provider",
+		version:    "v1",
+		resource:   "providers",
+		metaKinds:  []string{"Provider", "CustomResourceDefinition"},
+		packageDir: "package",
+		binary:     "provider",
+		mainPath:   "cmd/provider",
+	},
+	"function": {
+		name:       "Function",
+		version:    "v1beta1",
+		resource:   "functions",
+		metaKinds:  []string{"Function", "CustomResourceDefinition"},
+		packageDir: "package",
+		binary:     "function",
+		mainPath:   ".",
+	},
+}
+
+// Package of kind, like provider or Provider
+func New(kindName string) (Package, error) {
+	k, ok := kinds[strings.ToLower(kindName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown package kind %s, supported kinds: %s", kindName, strings.Join(Kinds(), ", "))
+	}
+	return k, nil
+}
+
+// Names of supported package kinds
+func Kinds() []string {
+	names := []string{}
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (k *kind) Kind() string {
+	return k.name
+}
+
+func (k *kind) gvr() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    apiGroup,
+		Version:  k.version,
+		Resource: k.resource,
+	}
+}
+
+func (k *kind) revisionGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    apiGroup,
+		Version:  k.version,
+		Resource: strings.TrimSuffix(k.resource, "s") + "revisions",
+	}
+}
+
+func (k *kind) apiName() string {
+	return k.resource + "." + apiGroup
+}
@@ -7,10 +7,8 @@ import (
 	condition "github.com/crossplane/crossplane-runtime/apis/common/v1"
 
 	configuration "github.com/crossplane/crossplane/apis/pkg/v1"
-	"github.com/google/go-containerregistry/pkg/v1/empty"
-	"github.com/web-seven/overlock/internal/image"
 	"github.com/web-seven/overlock/internal/kube"
-	"github.com/web-seven/overlock/internal/packages"
+	"github.com/web-seven/overlock/internal/xpkg"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -25,19 +23,33 @@ const (
 	apiPlural  = "configurations"
 )
 
+// Configuration package loaded to local registry and applied by name, it is
+// kept for plugins, operations are run by configuration kind of xpkg
 type Configuration struct {
-	Name  string
-	Image image.Image
-	packages.Package
+	// Package reference, or comma separated references to apply
+	Name    string
+	SignKey string
+	// Increase patch version of installed configuration on load
+	Upgrade bool
 }
 
 func New(name string) *Configuration {
 	return &Configuration{
-		Name:  name,
-		Image: image.Image{Image: empty.Image},
+		Name: name,
 	}
 }
 
+// Sign configuration image with key after push to local registry
+func (c *Configuration) WithSignKey(keyPath string) *Configuration {
+	c.SignKey = keyPath
+	return c
+}
+
+// Configuration kind of package operations
+func kind() (xpkg.Package, error) {
+	return xpkg.New("configuration")
+}
+
 func CheckHealthStatus(status []condition.Condition) bool {
 	healthStatus := false
 	for _, condition := range status {
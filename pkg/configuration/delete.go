@@ -4,25 +4,20 @@ import (
 	"context"
 	"strings"
 
-	crossv1 "github.com/crossplane/crossplane/apis/pkg/v1"
-	"github.com/web-seven/overlock/internal/engine"
 	"go.uber.org/zap"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/dynamic"
 )
 
+// Delete configurations by comma separated references or object names
 func DeleteConfiguration(ctx context.Context, urls string, dynamicClient *dynamic.DynamicClient, logger *zap.SugaredLogger) error {
-
+	pkg, err := kind()
+	if err != nil {
+		return err
+	}
 	for _, url := range strings.Split(urls, ",") {
-		cfg := crossv1.Configuration{}
-		engine.BuildPack(&cfg, url, map[string]string{})
-
-		err := dynamicClient.Resource(ResourceId()).Namespace("").Delete(ctx, cfg.GetName(), metav1.DeleteOptions{})
-		if err != nil {
+		if err := pkg.Delete(ctx, dynamicClient, url, logger); err != nil {
 			return err
 		}
 	}
-
-	logger.Info("Configuration(s) removed successfully.")
 	return nil
 }
@@ -42,6 +42,12 @@ type Environment struct {
 	providers                 []string
 	createAdminServiceAccount bool
 	adminServiceAccountName   string
+	orphanResources           bool
+	keepPolicyController      bool
+	keepCertManager           bool
+	verifyKey                 string
+	verifyImages              []string
+	lockFile                  string
 }
 
 // New Environment entity
@@ -163,6 +169,15 @@ func (e *Environment) Setup(ctx context.Context, logger *zap.SugaredLogger) erro
 	}
 	logger.Debug("Done")
 
+	if e.verifyKey != "" {
+		logger.Debug("Installing package signature verification policy")
+		err = e.addVerifyImagesPolicy(ctx, configClient)
+		if err != nil {
+			return err
+		}
+		logger.Debug("Done")
+	}
+
 	logger.Debug("Preparing engine")
 	installer, err := engine.GetEngine(configClient)
 	if err != nil {
@@ -198,6 +213,15 @@ func (e *Environment) Setup(ctx context.Context, logger *zap.SugaredLogger) erro
 		logger.Debug("Done")
 	}
 
+	if e.lockFile != "" {
+		logger.Debugf("Installing packages locked in %s", e.lockFile)
+		err = e.installLockedPackages(ctx, configClient, logger)
+		if err != nil {
+			return err
+		}
+		logger.Debug("Done")
+	}
+
 	// Create admin service account if requested
 	if e.createAdminServiceAccount {
 		logger.Debug("Creating admin service account")
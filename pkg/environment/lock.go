@@ -0,0 +1,31 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+
+	"github.com/web-seven/overlock/internal/lock"
+)
+
+// Install all packages of lock file pinned to digests
+func (e *Environment) installLockedPackages(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	l, err := lock.Load(e.lockFile)
+	if err != nil {
+		return err
+	}
+	if len(l.Packages) == 0 {
+		return fmt.Errorf("no packages found in lock file %s", e.lockFile)
+	}
+	_, err = lock.Install(ctx, config, l.Packages, logger)
+	return err
+}
+
+func (e *Environment) WithLockedPackages(locked bool, lockFile string) *Environment {
+	if locked {
+		e.lockFile = lockFile
+	}
+	return e
+}
@@ -0,0 +1,162 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/web-seven/overlock/internal/certmanager"
+	"github.com/web-seven/overlock/internal/engine"
+	"github.com/web-seven/overlock/internal/function"
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/namespace"
+	"github.com/web-seven/overlock/internal/policy"
+	"github.com/web-seven/overlock/internal/provider"
+	"github.com/web-seven/overlock/internal/resources"
+	"github.com/web-seven/overlock/pkg/configuration"
+	"github.com/web-seven/overlock/pkg/registry"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const uninstallTimeout = 10 * time.Minute
+
+// Uninstall removes everything overlock installed into the environment
+// context, leaving the cluster itself in place. Resources are removed in
+// dependency order: composite resources, packages, policies, local registry,
+// engine, policy controller, cert-manager and finally the system namespace.
+func (e *Environment) Uninstall(ctx context.Context, confirm bool, logger *zap.SugaredLogger) error {
+	if e.context == "" {
+		return fmt.Errorf("kubernetes context is required to uninstall environment")
+	}
+	if !confirm && !confirmationPrompt(fmt.Sprintf("Do you really want to uninstall overlock from context %s ?", e.context), logger) {
+		return nil
+	}
+
+	configClient, err := config.GetConfigWithContext(e.context)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(configClient)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Deleting composite resources")
+	err = resources.DeleteXResources(ctx, configClient, e.orphanResources, uninstallTimeout, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Deleting packages")
+	for _, gvr := range []schema.GroupVersionResource{
+		configuration.ResourceId(),
+		function.ResourceId(),
+		provider.ResourceId(),
+	} {
+		err = deletePackages(ctx, dynamicClient, gvr, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Deleting policies")
+	err = policy.DeleteManagedPolicies(ctx, configClient)
+	if err != nil {
+		return err
+	}
+
+	client, err := kube.Client(configClient)
+	if err != nil {
+		return err
+	}
+	if isLocal, _ := registry.IsLocalRegistry(ctx, client); isLocal {
+		logger.Info("Deleting local registry")
+		reg := registry.NewLocal()
+		err = reg.DeleteLocal(ctx, client, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Uninstalling engine")
+	err = engine.UninstallEngine(ctx, configClient, logger)
+	if err != nil {
+		return err
+	}
+
+	if !e.keepPolicyController {
+		logger.Info("Uninstalling policy controller")
+		err = policy.RemovePolicyController(ctx, configClient, policy.DefaultPolicyController)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !e.keepCertManager {
+		logger.Info("Uninstalling cert-manager")
+		err = certmanager.UninstallCertManager(ctx, configClient)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = certmanager.DeleteRegistryCertificate(ctx, configClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Deleting namespace")
+	err = namespace.DeleteNamespace(ctx, configClient)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Environment uninstalled successfully.")
+	return nil
+}
+
+// Delete all packages of the given type and wait until their revisions are gone
+func deletePackages(ctx context.Context, dc dynamic.Interface, gvr schema.GroupVersionResource, logger *zap.SugaredLogger) error {
+	params := kube.ResourceParams{
+		Dynamic:  dc,
+		Ctx:      ctx,
+		Group:    gvr.Group,
+		Version:  gvr.Version,
+		Resource: gvr.Resource,
+	}
+	pkgs, err := kube.GetKubeResources(params)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		logger.Debugf("Deleting %s %s", pkg.GetKind(), pkg.GetName())
+		err := dc.Resource(gvr).Delete(ctx, pkg.GetName(), metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return kube.WaitKubeResourcesDeleted(ctx, params, uninstallTimeout)
+}
+
+func (e *Environment) WithOrphanResources(orphan bool) *Environment {
+	e.orphanResources = orphan
+	return e
+}
+
+func (e *Environment) WithKeepPolicyController(keep bool) *Environment {
+	e.keepPolicyController = keep
+	return e
+}
+
+func (e *Environment) WithKeepCertManager(keep bool) *Environment {
+	e.keepCertManager = keep
+	return e
+}
@@ -0,0 +1,37 @@
+package environment
+
+import (
+	"context"
+	"os"
+
+	"github.com/web-seven/overlock/internal/policy"
+	"github.com/web-seven/overlock/pkg/registry"
+	"k8s.io/client-go/rest"
+)
+
+// Install policy which admits only packages signed with verify key. Packages
+// of local registry are verified when no image patterns are set.
+func (e *Environment) addVerifyImagesPolicy(ctx context.Context, config *rest.Config) error {
+	publicKey, err := os.ReadFile(e.verifyKey)
+	if err != nil {
+		return err
+	}
+	if _, err := registry.LoadVerificationKey(e.verifyKey); err != nil {
+		return err
+	}
+	images := e.verifyImages
+	if len(images) == 0 {
+		local := registry.NewLocal()
+		images = []string{local.LocalDomain() + "/*"}
+	}
+	return policy.AddVerifyImagesPolicy(ctx, config, &policy.VerifyImagesPolicy{
+		PublicKey:       string(publicKey),
+		ImageReferences: images,
+	})
+}
+
+func (e *Environment) WithPackageVerification(keyPath string, images []string) *Environment {
+	e.verifyKey = keyPath
+	e.verifyImages = images
+	return e
+}
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // InvalidConfigError represents configuration-related errors
@@ -152,3 +154,37 @@ func IsPackageNotFoundError(err error) bool {
 	var packageErr *PackageNotFoundError
 	return errors.As(err, &packageErr)
 }
+
+// TimeoutError represents waits which did not complete in time
+type TimeoutError struct {
+	Operation string
+	Timeout   time.Duration
+	// Resources still not in expected state
+	Pending []string
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	if len(e.Pending) > 0 {
+		return fmt.Sprintf("timeout after %s: %s: pending %s", e.Timeout, e.Operation, strings.Join(e.Pending, ", "))
+	}
+	return fmt.Sprintf("timeout after %s: %s", e.Timeout, e.Operation)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// NewTimeoutError creates a new TimeoutError
+func NewTimeoutError(operation string, timeout time.Duration, pending []string) *TimeoutError {
+	return &TimeoutError{
+		Operation: operation,
+		Timeout:   timeout,
+		Pending:   pending,
+	}
+}
+
+func IsTimeoutError(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
@@ -2,7 +2,9 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestInvalidConfigError(t *testing.T) {
@@ -68,6 +70,30 @@ func TestPackageNotFoundError(t *testing.T) {
 	}
 }
 
+func TestTimeoutError(t *testing.T) {
+	// Test TimeoutError with pending resources
+	err := NewTimeoutError("waiting for Provider(s) to become healthy", 2*time.Minute, []string{"provider-aws-s3", "provider-aws-ec2"})
+	expected := "timeout after 2m0s: waiting for Provider(s) to become healthy: pending provider-aws-s3, provider-aws-ec2"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	// Test TimeoutError without pending resources
+	err = NewTimeoutError("waiting for revision", time.Second, nil)
+	expected = "timeout after 1s: waiting for revision"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+
+	// Test error type checking through wrapping
+	if !IsTimeoutError(fmt.Errorf("install failed: %w", err)) {
+		t.Error("Expected IsTimeoutError to return true")
+	}
+	if IsTimeoutError(NewPackageNotFoundError("package", "registry", "version", "message")) {
+		t.Error("PackageNotFoundError should not match TimeoutError")
+	}
+}
+
 func TestErrorTypeDiscrimination(t *testing.T) {
 	configErr := NewInvalidConfigError("field", "value", "message")
 	k8sErr := NewKubernetesConnectionError("context", "host", "message")
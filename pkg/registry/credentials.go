@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials of a registry server
+type Credentials struct {
+	Server   string
+	Username string
+	Password string
+	Email    string
+}
+
+// Read password from the first line of stream
+func PasswordFromReader(stream io.Reader) (string, error) {
+	password, err := bufio.NewReader(stream).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password is empty")
+	}
+	return password, nil
+}
+
+// Read password from file, e.g. token mounted by secret manager
+func PasswordFromFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open password file")
+	}
+	defer file.Close()
+	return PasswordFromReader(file)
+}
+
+// Read password from environment variable
+func PasswordFromEnv(name string) (string, error) {
+	password, ok := os.LookupEnv(name)
+	if !ok || password == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return password, nil
+}
+
+// Fill credentials not set explicitly from other source, like Docker config.
+// Password of other source is not used for different explicit username.
+func (c Credentials) Merge(other Credentials) (Credentials, error) {
+	if c.Server == "" {
+		c.Server = other.Server
+	}
+	if c.Username == "" {
+		c.Username = other.Username
+	}
+	if c.Password == "" {
+		if c.Username != other.Username {
+			return c, fmt.Errorf("credentials found for %s, not %s, set password of %s explicitly", other.Username, c.Username, c.Username)
+		}
+		c.Password = other.Password
+	}
+	if c.Email == "" {
+		c.Email = other.Email
+	}
+	return c, nil
+}
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/pkg/errors"
+)
+
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// Read credentials for server from Docker config file. Credentials stored with
+// credsStore or credHelpers are resolved through docker-credential-* helpers.
+// When server is empty, config must contain credentials of exactly one server.
+func CredentialsFromDockerConfig(server string) (*Credentials, error) {
+	configFile, err := dockerconfig.Load(dockerconfig.Dir())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load Docker config")
+	}
+
+	key := dockerConfigKey(server)
+	if key == "" {
+		auths, err := configFile.GetAllCredentials()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read Docker credentials")
+		}
+		servers := []string{}
+		for s := range auths {
+			servers = append(servers, s)
+		}
+		sort.Strings(servers)
+		if len(servers) != 1 {
+			return nil, fmt.Errorf("registry server is required, Docker config has credentials for: %s", strings.Join(servers, ", "))
+		}
+		key = servers[0]
+	}
+
+	auth, err := configFile.GetAuthConfig(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get Docker credentials for %s", key)
+	}
+	if auth.Username == "" || auth.Password == "" {
+		if auth.IdentityToken != "" {
+			return nil, fmt.Errorf("credentials for %s use identity token, which could not be used as pull secret", key)
+		}
+		return nil, fmt.Errorf("credentials for %s not found in Docker config", key)
+	}
+
+	return &Credentials{
+		Server:   serverURL(key),
+		Username: auth.Username,
+		Password: auth.Password,
+		Email:    auth.Email,
+	}, nil
+}
+
+// Key of server in Docker config, which uses bare hostnames except of Docker Hub
+func dockerConfigKey(server string) string {
+	if server == "" {
+		return ""
+	}
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.TrimSuffix(host, "/")
+	switch host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return dockerHubConfigKey
+	}
+	return host
+}
+
+// Server URL with scheme as required by registry validation
+func serverURL(server string) string {
+	if strings.HasPrefix(server, "http://") || strings.HasPrefix(server, "https://") {
+		return server
+	}
+	return "https://" + server
+}
@@ -0,0 +1,356 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	semver "github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/namespace"
+	"github.com/web-seven/overlock/internal/packages"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const registryContainerName = "registry"
+
+// Repository of local registry with its tags
+type LocalImage struct {
+	Repository string
+	Tags       []string
+	Kind       string
+}
+
+// Details of image manifest stored in local registry
+type LocalImageDetails struct {
+	Reference   string
+	Digest      string
+	MediaType   string
+	Size        int64
+	Created     time.Time
+	Platforms   []string
+	Layers      int
+	Annotations map[string]string
+}
+
+// List repositories of local registry with tags, optionally limited to one repository
+func ListLocalImages(ctx context.Context, repository string, config *rest.Config, logger *zap.SugaredLogger) ([]LocalImage, error) {
+	images := []LocalImage{}
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		reg, err := name.NewRegistry(host)
+		if err != nil {
+			return err
+		}
+		repos := []string{repository}
+		if repository == "" {
+			repos, err = remote.Catalog(ctx, reg, opts...)
+			if err != nil {
+				return errors.Wrap(err, "failed to list repositories")
+			}
+		}
+		for _, repoName := range repos {
+			repo := reg.Repo(repoName)
+			tags, err := remote.List(repo, opts...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list tags of %s", repoName)
+			}
+			sortTags(tags)
+			images = append(images, LocalImage{Repository: repoName, Tags: tags})
+		}
+		return nil
+	})
+	return images, err
+}
+
+// Search repositories of local registry matching query, with package kind of latest tag
+func SearchLocalImages(ctx context.Context, query string, config *rest.Config, logger *zap.SugaredLogger) ([]LocalImage, error) {
+	images := []LocalImage{}
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		reg, err := name.NewRegistry(host)
+		if err != nil {
+			return err
+		}
+		repos, err := remote.Catalog(ctx, reg, opts...)
+		if err != nil {
+			return errors.Wrap(err, "failed to list repositories")
+		}
+		for _, repoName := range repos {
+			if !strings.Contains(repoName, query) {
+				continue
+			}
+			repo := reg.Repo(repoName)
+			tags, err := remote.List(repo, opts...)
+			if err != nil || len(tags) == 0 {
+				continue
+			}
+			sortTags(tags)
+			found := LocalImage{Repository: repoName, Tags: tags}
+			if img, err := remote.Image(repo.Tag(tags[len(tags)-1]), opts...); err == nil {
+				if meta, err := packages.ReadMeta(img); err == nil {
+					found.Kind = meta.Kind
+				}
+			}
+			images = append(images, found)
+		}
+		return nil
+	})
+	return images, err
+}
+
+// Delete image manifest referenced by tag or digest from local registry.
+// Registry deletes manifests by digest, so all tags of the same manifest are removed.
+func DeleteLocalImage(ctx context.Context, reference string, config *rest.Config, logger *zap.SugaredLogger) error {
+	return withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		ref, err := name.ParseReference(host + "/" + reference)
+		if err != nil {
+			return err
+		}
+		desc, err := remote.Head(ref, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "image %s not found", reference)
+		}
+		logger.Debugf("Deleting %s with digest %s", reference, desc.Digest)
+		return remote.Delete(ref.Context().Digest(desc.Digest.String()), opts...)
+	})
+}
+
+// Inspect image manifest referenced by tag or digest in local registry
+func InspectLocalImage(ctx context.Context, reference string, config *rest.Config, logger *zap.SugaredLogger) (*LocalImageDetails, error) {
+	details := &LocalImageDetails{Reference: reference}
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		ref, err := name.ParseReference(host + "/" + reference)
+		if err != nil {
+			return err
+		}
+		desc, err := remote.Get(ref, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "image %s not found", reference)
+		}
+		details.Digest = desc.Digest.String()
+		details.MediaType = string(desc.MediaType)
+		details.Size = desc.Size
+
+		if desc.MediaType.IsIndex() {
+			index, err := desc.ImageIndex()
+			if err != nil {
+				return err
+			}
+			manifest, err := index.IndexManifest()
+			if err != nil {
+				return err
+			}
+			details.Annotations = manifest.Annotations
+			for _, m := range manifest.Manifests {
+				if m.Platform != nil {
+					details.Platforms = append(details.Platforms, m.Platform.String())
+				}
+			}
+			return nil
+		}
+
+		img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		manifest, err := img.Manifest()
+		if err != nil {
+			return err
+		}
+		details.Annotations = manifest.Annotations
+		details.Layers = len(manifest.Layers)
+		cfg, err := img.ConfigFile()
+		if err != nil {
+			return err
+		}
+		details.Created = cfg.Created.Time
+		if cfg.OS != "" {
+			details.Platforms = append(details.Platforms, (&regv1.Platform{OS: cfg.OS, Architecture: cfg.Architecture, Variant: cfg.Variant}).String())
+		}
+		for k, v := range cfg.Config.Labels {
+			if details.Annotations == nil {
+				details.Annotations = map[string]string{}
+			}
+			if _, ok := details.Annotations[k]; !ok {
+				details.Annotations[k] = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+// Delete semantic version tags beyond the last keepPatches patch versions of
+// every minor version and run registry garbage collector to free blobs.
+// Returns references of deleted tags.
+func GarbageCollectLocal(ctx context.Context, keepPatches int, dryRun bool, config *rest.Config, logger *zap.SugaredLogger) ([]string, error) {
+	if keepPatches < 1 {
+		return nil, fmt.Errorf("at least one patch version must be kept")
+	}
+
+	deleted := []string{}
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		reg, err := name.NewRegistry(host)
+		if err != nil {
+			return err
+		}
+		repos, err := remote.Catalog(ctx, reg, opts...)
+		if err != nil {
+			return errors.Wrap(err, "failed to list repositories")
+		}
+		for _, repoName := range repos {
+			repo := reg.Repo(repoName)
+			tags, err := remote.List(repo, opts...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to list tags of %s", repoName)
+			}
+			keep, prune := pruneTags(tags, keepPatches)
+			if len(prune) == 0 {
+				continue
+			}
+
+			kept, removed := map[string]bool{}, map[string]bool{}
+			for _, tag := range keep {
+				desc, err := remote.Head(repo.Tag(tag), opts...)
+				if err != nil {
+					return err
+				}
+				kept[desc.Digest.String()] = true
+			}
+			for _, tag := range prune {
+				desc, err := remote.Head(repo.Tag(tag), opts...)
+				if err != nil {
+					return err
+				}
+				digest := desc.Digest.String()
+				if kept[digest] {
+					continue
+				}
+				deleted = append(deleted, repoName+":"+tag)
+				if dryRun || removed[digest] {
+					continue
+				}
+				removed[digest] = true
+				logger.Debugf("Deleting %s:%s", repoName, tag)
+				if err := remote.Delete(repo.Digest(digest), opts...); err != nil {
+					return errors.Wrapf(err, "failed to delete %s:%s", repoName, tag)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return deleted, nil
+	}
+	return deleted, runRegistryGarbageCollector(ctx, config, logger)
+}
+
+// Split tags on kept and pruned, keeping last patch versions of every minor
+// version. Tags which are not semantic versions are always kept.
+func pruneTags(tags []string, keepPatches int) (keep []string, prune []string) {
+	minors := map[string][]*semver.Version{}
+	originals := map[*semver.Version]string{}
+	for _, tag := range tags {
+		version, err := semver.NewVersion(tag)
+		if err != nil {
+			keep = append(keep, tag)
+			continue
+		}
+		minor := fmt.Sprintf("%d.%d", version.Major(), version.Minor())
+		minors[minor] = append(minors[minor], version)
+		originals[version] = tag
+	}
+	for _, versions := range minors {
+		sort.Sort(sort.Reverse(semver.Collection(versions)))
+		for i, version := range versions {
+			if i < keepPatches {
+				keep = append(keep, originals[version])
+			} else {
+				prune = append(prune, originals[version])
+			}
+		}
+	}
+	sortTags(prune)
+	return keep, prune
+}
+
+// Sort tags by semantic version when possible, otherwise alphabetically
+func sortTags(tags []string) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, erri := semver.NewVersion(tags[i])
+		vj, errj := semver.NewVersion(tags[j])
+		if erri == nil && errj == nil {
+			return vi.LessThan(vj)
+		}
+		return tags[i] < tags[j]
+	})
+}
+
+// Run registry garbage collector in registry container to remove unreferenced blobs
+func runRegistryGarbageCollector(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) error {
+	client, err := kube.Client(config)
+	if err != nil {
+		return err
+	}
+	pod, err := localRegistryPod(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace.Namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: registryContainerName,
+			Command:   []string{"registry", "garbage-collect", "--delete-untagged", configMountPath + "/config.yml"},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	if err != nil {
+		return err
+	}
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: out, Stderr: errOut})
+	logger.Debug(out.String())
+	if err != nil {
+		return errors.Wrapf(err, "registry garbage collector failed: %s", strings.TrimSpace(errOut.String()))
+	}
+	return nil
+}
+
+// Name of running local registry pod
+func localRegistryPod(ctx context.Context, client *kubernetes.Clientset) (string, error) {
+	pods := client.CoreV1().Pods(namespace.Namespace)
+	regs, err := pods.List(ctx, v1.ListOptions{LabelSelector: "app=" + deployName, FieldSelector: "status.phase=Running"})
+	if err != nil {
+		return "", err
+	}
+	if len(regs.Items) == 0 {
+		return "", fmt.Errorf("local registry not found")
+	}
+	return regs.Items[0].GetName(), nil
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Credentials of registries configured in cluster by registry host
+type registryKeychain map[string]RegistryAuth
+
+// Keychain with credentials of registries configured in cluster, Docker
+// config is used for other registries
+func Keychain(ctx context.Context, client *kubernetes.Clientset) (authn.Keychain, error) {
+	registries, err := Registries(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	keychain := registryKeychain{}
+	for _, reg := range registries {
+		reg.Name = reg.GetName()
+		auth, err := reg.Auth()
+		if err != nil {
+			continue
+		}
+		for _, server := range []string{auth.Server, reg.Annotations[RegistryServerLabel]} {
+			if host := RegistryHost(server); host != "" {
+				keychain[host] = auth
+			}
+		}
+	}
+	return authn.NewMultiKeychain(keychain, authn.DefaultKeychain), nil
+}
+
+func (k registryKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	auth, ok := k[resource.RegistryStr()]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return &authn.Basic{Username: auth.Username, Password: auth.Password}, nil
+}
+
+// Host of registry server, like index.docker.io for https://index.docker.io/v1/
+func RegistryHost(server string) string {
+	if server == "" {
+		return ""
+	}
+	if strings.Contains(server, "://") {
+		u, err := url.Parse(server)
+		if err != nil {
+			return ""
+		}
+		server = u.Host
+	}
+	reg, err := name.NewRegistry(strings.TrimSuffix(server, "/"))
+	if err != nil {
+		return ""
+	}
+	return reg.RegistryStr()
+}
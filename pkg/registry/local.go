@@ -1,34 +1,27 @@
 package registry
 
 import (
-	"bytes"
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
-	"net"
-	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	regv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/web-seven/overlock/internal/certmanager"
-	"github.com/web-seven/overlock/internal/kube"
 	"github.com/web-seven/overlock/internal/namespace"
 	"github.com/web-seven/overlock/internal/policy"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/portforward"
-	"k8s.io/client-go/transport/spdy"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -36,23 +29,27 @@ import (
 )
 
 const (
-	deployName          = "overlock-registry"
-	svcName             = "registry"
-	deployPort          = 5000
-	nginxPortHTTP       = 80
-	nginxPortHTTPS      = 443
-	svcPort             = 80
-	svcPortTLS          = 443
-	nodePort            = 30100
-	tlsCertPath         = "/certs/tls.crt"
-	tlsKeyPath          = "/certs/tls.key"
-	tlsVolumeName       = "registry-tls"
-	tlsMountPath        = "/certs"
-	configVolumeName    = "registry-config"
-	configMountPath     = "/etc/docker/registry"
-	configMapName       = "registry-config"
-	nginxConfigMapName  = "nginx-proxy-config"
+	deployName           = "overlock-registry"
+	svcName              = "registry"
+	deployPort           = 5000
+	nginxPortHTTP        = 80
+	nginxPortHTTPS       = 443
+	svcPort              = 80
+	svcPortTLS           = 443
+	nodePort             = 30100
+	tlsCertPath          = "/certs/tls.crt"
+	tlsKeyPath           = "/certs/tls.key"
+	tlsVolumeName        = "registry-tls"
+	tlsMountPath         = "/certs"
+	configVolumeName     = "registry-config"
+	configMountPath      = "/etc/docker/registry"
+	configMapName        = "registry-config"
+	nginxConfigMapName   = "nginx-proxy-config"
 	nginxConfigMountPath = "/etc/nginx/conf.d"
+	dataVolumeName       = "registry-data"
+	dataMountPath        = "/var/lib/registry"
+	dataClaimName        = "registry-data"
+	defaultStorageSize   = "10Gi"
 )
 
 var (
@@ -61,6 +58,12 @@ var (
 	}
 )
 
+// Object of local registry with function applying desired state on update
+type localObject struct {
+	obj    ctrl.Object
+	mutate controllerutil.MutateFn
+}
+
 type RegistryReconciler struct {
 	client.Client
 	context.CancelFunc
@@ -73,6 +76,16 @@ func (r *Registry) CreateLocal(ctx context.Context, client *kubernetes.Clientset
 		return err
 	}
 
+	dataVolume, dataClaim, err := r.dataVolume()
+	if err != nil {
+		return err
+	}
+
+	proxyConfig, err := r.Proxy.config()
+	if err != nil {
+		return err
+	}
+
 	// Install cert-manager and create TLS certificate
 	logger.Debug("Installing cert-manager")
 	if err := certmanager.InstallCertManager(ctx, configClient); err != nil {
@@ -110,7 +123,7 @@ storage:
     enabled: true
 http:
   addr: :5000
-`
+` + proxyConfig
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: v1.ObjectMeta{
 			Name:      configMapName,
@@ -174,6 +187,9 @@ server {
 			Selector: &v1.LabelSelector{
 				MatchLabels: matchLabels,
 			},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: v1.ObjectMeta{
 					Labels: matchLabels,
@@ -181,8 +197,9 @@ server {
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  "registry",
+							Name:  registryContainerName,
 							Image: "registry:2",
+							Env:   r.Proxy.env(),
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          "oci",
@@ -196,6 +213,10 @@ server {
 									MountPath: configMountPath,
 									ReadOnly:  true,
 								},
+								{
+									Name:      dataVolumeName,
+									MountPath: dataMountPath,
+								},
 							},
 						},
 						{
@@ -228,6 +249,7 @@ server {
 						},
 					},
 					Volumes: []corev1.Volume{
+						dataVolume,
 						{
 							Name: tlsVolumeName,
 							VolumeSource: corev1.VolumeSource{
@@ -291,8 +313,34 @@ server {
 	corev1.AddToScheme(scheme)
 	appsv1.AddToScheme(scheme)
 	ctrlClient, _ := ctrl.New(configClient, ctrl.Options{Scheme: scheme})
-	for _, res := range []ctrl.Object{configMap, nginxConfigMap, deploy, svc} {
-		_, err := controllerutil.CreateOrUpdate(ctx, ctrlClient, res, func() error { return nil })
+	noop := func() error { return nil }
+	configData := configMap.Data
+	deploySpec := deploy.Spec.DeepCopy()
+	objects := []localObject{
+		{configMap, func() error { configMap.Data = configData; return nil }},
+		{nginxConfigMap, noop},
+	}
+	if dataClaim != nil {
+		objects = append(objects, localObject{dataClaim, noop})
+	}
+	// Credentials of previous upstream are removed when registry is created
+	// again without proxy or without its credentials
+	if proxySecret := r.Proxy.secret(); proxySecret != nil {
+		proxyData := proxySecret.StringData
+		objects = append(objects, localObject{proxySecret, func() error { proxySecret.StringData = proxyData; return nil }})
+	} else if err := deleteProxySecret(ctx, client); err != nil {
+		return err
+	}
+	objects = append(objects, []localObject{
+		{deploy, func() error {
+			deploy.Spec.Strategy = deploySpec.Strategy
+			deploy.Spec.Template = deploySpec.Template
+			return nil
+		}},
+		{svc, noop},
+	}...)
+	for _, res := range objects {
+		_, err := controllerutil.CreateOrUpdate(ctx, ctrlClient, res.obj, res.mutate)
 		if err != nil {
 			return err
 		}
@@ -327,9 +375,11 @@ server {
 				err = policy.AddRegistryPolicy(ctx,
 					configClient,
 					&policy.RegistryPolicy{
-						Name:     r.Name,
-						Url:      r.Server,
-						NodePort: fmt.Sprintf("%v", svc.Spec.Ports[0].NodePort),
+						Name:       r.Name,
+						Url:        r.Server,
+						NodePort:   fmt.Sprintf("%v", svc.Spec.Ports[0].NodePort),
+						Namespaces: r.Policy.Namespaces,
+						Labels:     r.Policy.Labels,
 					},
 				)
 				if err != nil {
@@ -346,8 +396,7 @@ server {
 // Delete in cluster registry
 func (r *Registry) DeleteLocal(ctx context.Context, client *kubernetes.Clientset, logger *zap.SugaredLogger) error {
 	svcs := client.CoreV1().Services(namespace.Namespace)
-	eSvc, _ := svcs.Get(ctx, svcName, v1.GetOptions{})
-	if eSvc != nil {
+	if _, err := svcs.Get(ctx, svcName, v1.GetOptions{}); err == nil {
 		err := svcs.Delete(ctx, svcName, v1.DeleteOptions{})
 		if err != nil {
 			return err
@@ -356,8 +405,7 @@ func (r *Registry) DeleteLocal(ctx context.Context, client *kubernetes.Clientset
 		logger.Warnf("Service %s not found", svcName)
 	}
 	deployments := client.AppsV1().Deployments(namespace.Namespace)
-	eDeploy, _ := deployments.Get(ctx, deployName, v1.GetOptions{})
-	if eDeploy != nil {
+	if _, err := deployments.Get(ctx, deployName, v1.GetOptions{}); err == nil {
 		err := deployments.Delete(ctx, deployName, v1.DeleteOptions{})
 		if err != nil {
 			return err
@@ -365,184 +413,168 @@ func (r *Registry) DeleteLocal(ctx context.Context, client *kubernetes.Clientset
 	} else {
 		logger.Warnf("Deployment %s not found", deployName)
 	}
+	if err := deleteProxySecret(ctx, client); err != nil {
+		return err
+	}
+	claims := client.CoreV1().PersistentVolumeClaims(namespace.Namespace)
+	err := claims.Delete(ctx, dataClaimName, v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
 	return nil
 }
 
-func IsLocalRegistry(ctx context.Context, client *kubernetes.Clientset) (bool, error) {
+// Volume for registry data, backed by host path or persistent volume claim
+func (r *Registry) dataVolume() (corev1.Volume, *corev1.PersistentVolumeClaim, error) {
+	if r.Storage.HostPath != "" {
+		hostPathType := corev1.HostPathDirectoryOrCreate
+		return corev1.Volume{
+			Name: dataVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: r.Storage.HostPath,
+					Type: &hostPathType,
+				},
+			},
+		}, nil, nil
+	}
 
-	pods := client.CoreV1().Pods(namespace.Namespace)
-	regs, err := pods.List(ctx, v1.ListOptions{Limit: 1, LabelSelector: "app=" + deployName})
+	size := r.Storage.Size
+	if size == "" {
+		size = defaultStorageSize
+	}
+	quantity, err := resource.ParseQuantity(size)
 	if err != nil {
-		return false, err
+		return corev1.Volume{}, nil, fmt.Errorf("invalid registry storage size %s: %w", size, err)
 	}
-	if len(regs.Items) == 0 {
-		return false, fmt.Errorf("not found local registry")
+
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      dataClaimName,
+			Namespace: namespace.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: quantity,
+				},
+			},
+		},
+	}
+	if r.Storage.Class != "" {
+		claim.Spec.StorageClassName = &r.Storage.Class
 	}
-	return true, nil
-}
 
-func PushLocalRegistry(ctx context.Context, imageName string, image regv1.Image, config *rest.Config, logger *zap.SugaredLogger) error {
+	return corev1.Volume{
+		Name: dataVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: dataClaimName,
+			},
+		},
+	}, claim, nil
+}
 
-	client, err := kube.Client(config)
-	if err != nil {
-		return err
-	}
+func IsLocalRegistry(ctx context.Context, client *kubernetes.Clientset) (bool, error) {
 
 	pods := client.CoreV1().Pods(namespace.Namespace)
 	regs, err := pods.List(ctx, v1.ListOptions{Limit: 1, LabelSelector: "app=" + deployName})
 	if err != nil {
-		return err
-	}
-
-	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
-	if err != nil {
-		return err
+		return false, err
 	}
-
-	lPort, err := getFreePort()
-	if err != nil {
-		return err
+	if len(regs.Items) == 0 {
+		return false, fmt.Errorf("not found local registry")
 	}
+	return true, nil
+}
 
-	logger.Debugf("Found local registry with name: %s", regs.Items[0].GetName())
-
-	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace.Namespace, regs.Items[0].GetName())
-	hostIP := strings.TrimLeft(config.Host, "htps:/")
-	serverURL := url.URL{Scheme: "https", Path: path, Host: hostIP}
+// Option of push to local registry
+type PushOption func(*pushOptions)
 
-	logger.Debugf("Dialer server URL: %s", serverURL.String())
+type pushOptions struct {
+	signKey string
+}
 
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, &serverURL)
-	stopChan, readyChan := make(chan struct{}, 1), make(chan struct{}, 1)
-	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
-	forwarder, err := portforward.New(dialer, []string{fmt.Sprint(lPort) + ":" + fmt.Sprint(deployPort)}, stopChan, readyChan, out, errOut)
-	if err != nil {
-		return err
+// Sign pushed image or index with private key, signature is pushed next to it
+func WithSignKey(keyPath string) PushOption {
+	return func(o *pushOptions) {
+		o.signKey = keyPath
 	}
+}
 
-	go func() {
-		for range readyChan {
-		}
-		if len(errOut.String()) != 0 {
-			close(stopChan)
-		} else if len(out.String()) != 0 {
-			logger.Debug(out.String())
-		}
-		refName := "localhost:" + fmt.Sprint(lPort) + "/" + imageName
+// Push image to local registry through shared tunnel
+func PushLocalRegistry(ctx context.Context, imageName string, image regv1.Image, config *rest.Config, logger *zap.SugaredLogger, pushOpts ...PushOption) error {
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		refName := host + "/" + imageName
 		logger.Debugf("Try to push to reference: %s", refName)
 		ref, err := name.ParseReference(refName)
 		if err != nil {
-			logger.Error(err)
-			close(stopChan)
-			return
-		}
-		// Use insecure transport for self-signed certificate
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			return err
 		}
-		err = remote.Write(ref, image, remote.WithTransport(transport))
-		if err != nil {
-			logger.Error(err)
-			close(stopChan)
-			return
+		if err := remote.Write(ref, image, opts...); err != nil {
+			return err
 		}
 		logger.Debug("Pushed to remote registry.")
-		close(stopChan)
-	}()
-
-	if err = forwarder.ForwardPorts(); err != nil {
+		return nil
+	})
+	if err != nil {
 		return err
 	}
-	return nil
+	return signPushed(ctx, imageName, image, config, logger, pushOpts)
 }
 
-func getFreePort() (port int, err error) {
-	var a *net.TCPAddr
-	if a, err = net.ResolveTCPAddr("tcp", "localhost:0"); err == nil {
-		var l *net.TCPListener
-		if l, err = net.ListenTCP("tcp", a); err == nil {
-			defer func() { _ = l.Close() }()
-			return l.Addr().(*net.TCPAddr).Port, nil
+// Push image index with images of all platforms to local registry
+func PushLocalRegistryIndex(ctx context.Context, imageName string, index regv1.ImageIndex, config *rest.Config, logger *zap.SugaredLogger, pushOpts ...PushOption) error {
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		refName := host + "/" + imageName
+		logger.Debugf("Try to push index to reference: %s", refName)
+		ref, err := name.ParseReference(refName)
+		if err != nil {
+			return err
 		}
-	}
-	return
-}
-
-// ListLocalRegistryTags lists all tags for an image in the local registry
-func ListLocalRegistryTags(ctx context.Context, imageName string, config *rest.Config, logger *zap.SugaredLogger) ([]string, error) {
-	client, err := kube.Client(config)
-	if err != nil {
-		return nil, err
-	}
-
-	pods := client.CoreV1().Pods(namespace.Namespace)
-	regs, err := pods.List(ctx, v1.ListOptions{Limit: 1, LabelSelector: "app=" + deployName})
+		if err := remote.WriteIndex(ref, index, opts...); err != nil {
+			return err
+		}
+		logger.Debug("Pushed index to remote registry.")
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return signPushed(ctx, imageName, index, config, logger, pushOpts)
+}
 
-	if len(regs.Items) == 0 {
-		return nil, fmt.Errorf("local registry not found")
+// Sign pushed manifest when sign key option is set
+func signPushed(ctx context.Context, imageName string, manifest interface{ Digest() (regv1.Hash, error) }, config *rest.Config, logger *zap.SugaredLogger, pushOpts []PushOption) error {
+	o := &pushOptions{}
+	for _, opt := range pushOpts {
+		opt(o)
 	}
-
-	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
-	if err != nil {
-		return nil, err
+	if o.signKey == "" {
+		return nil
 	}
-
-	lPort, err := getFreePort()
-	if err != nil {
-		return nil, err
-	}
-
-	logger.Debugf("Found local registry with name: %s", regs.Items[0].GetName())
-
-	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace.Namespace, regs.Items[0].GetName())
-	hostIP := strings.TrimLeft(config.Host, "htps:/")
-	serverURL := url.URL{Scheme: "https", Path: path, Host: hostIP}
-
-	logger.Debugf("Dialer server URL: %s", serverURL.String())
-
-	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, &serverURL)
-	stopChan, readyChan := make(chan struct{}, 1), make(chan struct{}, 1)
-	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
-	forwarder, err := portforward.New(dialer, []string{fmt.Sprint(lPort) + ":" + fmt.Sprint(deployPort)}, stopChan, readyChan, out, errOut)
+	digest, err := manifest.Digest()
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return signLocal(ctx, imageName, digest, o.signKey, config, logger)
+}
 
+// ListLocalRegistryTags lists all tags for an image in the local registry
+func ListLocalRegistryTags(ctx context.Context, imageName string, config *rest.Config, logger *zap.SugaredLogger) ([]string, error) {
 	var tags []string
-	var listErr error
-
-	go func() {
-		for range readyChan {
-		}
-		if len(errOut.String()) != 0 {
-			close(stopChan)
-			return
-		}
-		repoName := "localhost:" + fmt.Sprint(lPort) + "/" + imageName
+	err := withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		repoName := host + "/" + imageName
 		logger.Debugf("Listing tags for repository: %s", repoName)
 		repo, err := name.NewRepository(repoName)
 		if err != nil {
-			listErr = err
-			close(stopChan)
-			return
-		}
-		// Use insecure transport for self-signed certificate
-		transport := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		tags, listErr = remote.List(repo, remote.WithTransport(transport))
-		if listErr != nil {
-			logger.Debugf("Failed to list tags: %v", listErr)
+			return err
 		}
-		close(stopChan)
-	}()
-
-	if err = forwarder.ForwardPorts(); err != nil {
-		return nil, err
-	}
-
-	return tags, listErr
+		tags, err = remote.List(repo, opts...)
+		return err
+	})
+	return tags, err
 }
@@ -0,0 +1,226 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/image"
+	"github.com/web-seven/overlock/internal/packages"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+var dependencyKinds = []string{"configuration", "provider", "function"}
+
+// Copy package and its dependency tree into local registry. When rewrite is
+// set, dependencies of mirrored packages reference the local registry.
+// Returns in cluster references of mirrored packages, root package last.
+func Mirror(ctx context.Context, ref string, rewrite bool, config *rest.Config, logger *zap.SugaredLogger) ([]string, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	keychain, err := Keychain(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("Resolving dependencies of %s", ref)
+	tree, err := packages.ResolveTree(ref, crane.WithContext(ctx), crane.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return nil, err
+	}
+
+	local := NewLocal()
+	domain := local.LocalDomain()
+	mirrored := []string{}
+	err = tree.Walk(func(node *packages.Node) error {
+		imageName, err := localImageName(node.Repository, node.Version)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Mirroring %s", node.Reference())
+		rewriteNode := rewrite && len(node.Dependencies) > 0
+		if node.Index != nil {
+			index := node.Index
+			if rewriteNode {
+				index, err = rewriteIndexDependencies(index, domain)
+				if err != nil {
+					return errors.Wrapf(err, "failed to rewrite dependencies of %s", node.Reference())
+				}
+			}
+			if err := PushLocalRegistryIndex(ctx, imageName, index, config, logger); err != nil {
+				return errors.Wrapf(err, "failed to push %s", node.Reference())
+			}
+		} else {
+			img := node.Image
+			if rewriteNode {
+				img, err = rewriteDependencies(img, domain)
+				if err != nil {
+					return errors.Wrapf(err, "failed to rewrite dependencies of %s", node.Reference())
+				}
+			}
+			if err := PushLocalRegistry(ctx, imageName, img, config, logger); err != nil {
+				return errors.Wrapf(err, "failed to push %s", node.Reference())
+			}
+		}
+		mirrored = append(mirrored, domain+"/"+imageName)
+		return nil
+	})
+	return mirrored, err
+}
+
+// Name of image in local registry, keeping repository path of origin
+func localImageName(repository string, version string) (string, error) {
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return "", err
+	}
+	if _, err := name.NewTag("local/" + repo.RepositoryStr() + ":" + version); err != nil {
+		return "", fmt.Errorf("version %s of %s could not be used as tag", version, repository)
+	}
+	return repo.RepositoryStr() + ":" + version, nil
+}
+
+// Point dependencies in package metadata to registry domain and replace
+// package base layer with rewritten package.yaml
+func rewriteDependencies(img regv1.Image, domain string) (regv1.Image, error) {
+	content, err := packages.ReadPackageFile(img)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := packages.SplitDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range docs {
+		if !packages.IsMetaDocument(doc) {
+			continue
+		}
+		docs[i], err = rewriteMeta(doc, domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	layer, err := image.LoadBinaryLayer(bytes.Join(docs, []byte("\n---\n")), packages.PackageFile, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return replaceBaseLayer(img, layer)
+}
+
+// Rewrite dependencies of every platform image of multi-platform package
+func rewriteIndexDependencies(index regv1.ImageIndex, domain string) (regv1.ImageIndex, error) {
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	addendums := []mutate.IndexAddendum{}
+	for _, desc := range manifest.Manifests {
+		img, err := index.Image(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		img, err = rewriteDependencies(img, domain)
+		if err != nil {
+			return nil, err
+		}
+		addendums = append(addendums, mutate.IndexAddendum{
+			Add: img,
+			Descriptor: regv1.Descriptor{
+				Platform:    desc.Platform,
+				Annotations: desc.Annotations,
+			},
+		})
+	}
+	out := mutate.IndexMediaType(empty.Index, manifest.MediaType)
+	return mutate.AppendManifests(out, addendums...), nil
+}
+
+func rewriteMeta(doc []byte, domain string) ([]byte, error) {
+	meta := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &meta); err != nil {
+		return nil, err
+	}
+	spec, _ := meta["spec"].(map[string]interface{})
+	deps, _ := spec["dependsOn"].([]interface{})
+	for _, d := range deps {
+		dep, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, kind := range dependencyKinds {
+			pkg, ok := dep[kind].(string)
+			if !ok {
+				continue
+			}
+			repo, err := name.NewRepository(pkg)
+			if err != nil {
+				return nil, err
+			}
+			dep[kind] = domain + "/" + repo.RepositoryStr()
+		}
+	}
+	return yaml.Marshal(meta)
+}
+
+// Rebuild image with base layer replaced, or appended when image has no annotated layers
+func replaceBaseLayer(img regv1.Image, base regv1.Layer) (regv1.Image, error) {
+	baseAnnotations := map[string]string{image.AnnotationKey: packages.BaseAnnotation}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.DeepCopy()
+	cfg.RootFS.DiffIDs = nil
+	cfg.History = nil
+
+	baseDigest, err := base.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Config.Labels == nil {
+		cfg.Config.Labels = map[string]string{}
+	}
+
+	replaced := false
+	addendums := []mutate.Addendum{}
+	for _, desc := range manifest.Layers {
+		if desc.Annotations[image.AnnotationKey] == packages.BaseAnnotation && !replaced {
+			delete(cfg.Config.Labels, image.AnnotationKey+":"+desc.Digest.String())
+			addendums = append(addendums, mutate.Addendum{Layer: base, Annotations: baseAnnotations})
+			replaced = true
+			continue
+		}
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		addendums = append(addendums, mutate.Addendum{Layer: layer, Annotations: desc.Annotations, MediaType: desc.MediaType})
+	}
+	if !replaced {
+		addendums = append(addendums, mutate.Addendum{Layer: base, Annotations: baseAnnotations})
+	}
+	cfg.Config.Labels[image.AnnotationKey+":"+baseDigest.String()] = packages.BaseAnnotation
+
+	out := mutate.MediaType(empty.Image, manifest.MediaType)
+	out = mutate.ConfigMediaType(out, manifest.Config.MediaType)
+	out, err = mutate.ConfigFile(out, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return mutate.Append(out, addendums...)
+}
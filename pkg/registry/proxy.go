@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/web-seven/overlock/internal/namespace"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	proxySecretName  = "registry-proxy-auth"
+	proxyUsernameKey = "username"
+	proxyPasswordKey = "password"
+)
+
+// Upstream of local registry working as pull-through cache
+type LocalProxy struct {
+	RemoteURL string
+	Username  string
+	Password  string
+}
+
+// Configure local registry as pull-through cache of remote registry
+func (r *Registry) WithProxy(remoteURL string, creds *Credentials) {
+	r.Proxy = LocalProxy{RemoteURL: remoteURL}
+	if creds != nil {
+		r.Proxy.Username = creds.Username
+		r.Proxy.Password = creds.Password
+	}
+}
+
+// Credentials for proxied upstream taken from existing registry, by name or
+// by matching server. Returns nil when upstream has no registered credentials.
+func ProxyCredentials(ctx context.Context, client *kubernetes.Clientset, remoteURL string, name string) (*Credentials, error) {
+	if name != "" {
+		reg, err := Get(ctx, client, name)
+		if err != nil {
+			return nil, err
+		}
+		return credentialsOf(reg)
+	}
+
+	registries, err := Registries(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, reg := range registries {
+		server := reg.Annotations[RegistryServerLabel]
+		if server != "" && dockerConfigKey(server) == dockerConfigKey(remoteURL) {
+			reg.Name = reg.GetName()
+			return credentialsOf(reg)
+		}
+	}
+	return nil, nil
+}
+
+func credentialsOf(reg *Registry) (*Credentials, error) {
+	auth, err := reg.Auth()
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		Server:   auth.Server,
+		Username: auth.Username,
+		Password: auth.Password,
+		Email:    auth.Email,
+	}, nil
+}
+
+// Proxy section of registry configuration, credentials are passed by environment
+func (p LocalProxy) config() (string, error) {
+	if p.RemoteURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(p.RemoteURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid proxy remote URL %s, expected http(s)://host", p.RemoteURL)
+	}
+	return fmt.Sprintf("proxy:\n  remoteurl: %q\n", p.RemoteURL), nil
+}
+
+// Secret with credentials of proxied upstream
+func (p LocalProxy) secret() *corev1.Secret {
+	if p.Username == "" {
+		return nil
+	}
+	return &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      proxySecretName,
+			Namespace: namespace.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			proxyUsernameKey: p.Username,
+			proxyPasswordKey: p.Password,
+		},
+	}
+}
+
+// Environment of registry container with credentials of proxied upstream
+func (p LocalProxy) env() []corev1.EnvVar {
+	if p.Username == "" {
+		return nil
+	}
+	fromSecret := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: proxySecretName},
+				Key:                  key,
+			},
+		}
+	}
+	return []corev1.EnvVar{
+		{Name: "REGISTRY_PROXY_USERNAME", ValueFrom: fromSecret(proxyUsernameKey)},
+		{Name: "REGISTRY_PROXY_PASSWORD", ValueFrom: fromSecret(proxyPasswordKey)},
+	}
+}
+
+// Delete secret with credentials of proxied upstream, when it exists
+func deleteProxySecret(ctx context.Context, client *kubernetes.Clientset) error {
+	err := client.CoreV1().Secrets(namespace.Namespace).Delete(ctx, proxySecretName, v1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
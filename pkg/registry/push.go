@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Authenticator of remote registry of reference, with credentials of registry
+// secret when name is set, otherwise with credentials of Docker config.
+// Registry secret must have credentials for registry host of reference.
+func RemoteAuth(ctx context.Context, client *kubernetes.Clientset, registryName string, ref name.Reference) (authn.Authenticator, error) {
+	if registryName == "" {
+		return authn.DefaultKeychain.Resolve(ref.Context())
+	}
+	reg, err := Get(ctx, client, registryName)
+	if err != nil {
+		return nil, err
+	}
+	regConf := RegistryConfig{}
+	if err := json.Unmarshal(reg.Data[".dockerconfigjson"], &regConf); err != nil {
+		return nil, errors.Wrap(err, "failed to parse registry secret")
+	}
+	host := ref.Context().RegistryStr()
+	if RegistryHost(reg.Server) == host {
+		if auth, ok := regConf.Auths[reg.Server]; ok {
+			return &authn.Basic{Username: auth.Username, Password: auth.Password}, nil
+		}
+	}
+	for server, auth := range regConf.Auths {
+		if RegistryHost(server) == host || RegistryHost(auth.Server) == host {
+			return &authn.Basic{Username: auth.Username, Password: auth.Password}, nil
+		}
+	}
+	return nil, fmt.Errorf("registry %s has no credentials for %s", registryName, host)
+}
+
+// Push image to remote registry, returns reference of pushed image by digest
+func PushRemote(ctx context.Context, ref name.Reference, img regv1.Image, auth authn.Authenticator) (string, error) {
+	if err := remote.Write(ref, img, remote.WithContext(ctx), remote.WithAuth(auth)); err != nil {
+		return "", errors.Wrapf(err, "failed to push %s", ref)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Digest(digest.String()).String(), nil
+}
+
+// Push multi-platform index to remote registry, returns reference of pushed
+// index by digest
+func PushRemoteIndex(ctx context.Context, ref name.Reference, index regv1.ImageIndex, auth authn.Authenticator) (string, error) {
+	if err := remote.WriteIndex(ref, index, remote.WithContext(ctx), remote.WithAuth(auth)); err != nil {
+		return "", errors.Wrapf(err, "failed to push %s", ref)
+	}
+	digest, err := index.Digest()
+	if err != nil {
+		return "", err
+	}
+	return ref.Context().Digest(digest.String()).String(), nil
+}
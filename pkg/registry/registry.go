@@ -36,7 +36,7 @@ var (
 type RegistryAuth struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"omitempty,email"`
 	Server   string `json:"server" validate:"required,http_url"`
 	Auth     string `json:"auth"`
 }
@@ -45,6 +45,13 @@ type RegistryConfig struct {
 	Auths map[string]RegistryAuth `json:"auths"`
 }
 
+// Storage of local registry data. HostPath takes precedence over volume claim.
+type LocalStorage struct {
+	Size     string
+	Class    string
+	HostPath string
+}
+
 type Registry struct {
 	Config  RegistryConfig
 	Default bool
@@ -52,9 +59,18 @@ type Registry struct {
 	Context string
 	Server  string
 	Name    string
+	Storage LocalStorage
+	Proxy   LocalProxy
+	Policy  PolicyScope
 	corev1.Secret
 }
 
+// Pods which images of local registry are rewritten by policy, all if empty
+type PolicyScope struct {
+	Namespaces []string
+	Labels     map[string]string
+}
+
 // Return regestires from requested context
 func Registries(ctx context.Context, client *kubernetes.Clientset) ([]*Registry, error) {
 	secrets, err := secretClient(client).
@@ -400,6 +416,23 @@ func (r *Registry) WithContext(c string) {
 	r.Context = c
 }
 
+// Storage used by local registry for images data
+func (r *Registry) WithStorage(size string, class string, hostPath string) {
+	r.Storage = LocalStorage{
+		Size:     size,
+		Class:    class,
+		HostPath: hostPath,
+	}
+}
+
+// Scope of policy rewriting images of local registry
+func (r *Registry) WithPolicyScope(namespaces []string, labels map[string]string) {
+	r.Policy = PolicyScope{
+		Namespaces: namespaces,
+		Labels:     labels,
+	}
+}
+
 // Domain of primary registry
 func (r *Registry) Domain() (string, error) {
 	if r.Local {
@@ -0,0 +1,264 @@
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	regv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/packages"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+)
+
+// Cosign signature format, signatures are stored in the same repository
+// under sha256-<digest>.sig tag as simple signing payload layer.
+const (
+	signatureMediaType    = "application/vnd.dev.cosign.simplesigning.v1+json"
+	signatureAnnotation   = "dev.cosignproject.cosign/signature"
+	signatureType         = "cosign container image signature"
+	signatureTagSuffix    = ".sig"
+	privateKeyPEMType     = "PRIVATE KEY"
+	ecPrivateKeyPEMType   = "EC PRIVATE KEY"
+	publicKeyPEMType      = "PUBLIC KEY"
+	cosignEncryptedKeyPEM = "ENCRYPTED SIGSTORE PRIVATE KEY"
+)
+
+type simpleSigning struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+// Generate ECDSA P-256 key pair for package signing
+func GenerateSigningKey(privatePath string, publicPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: privateDER}), 0o600)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: publicDER}), 0o644)
+}
+
+// Load ECDSA private key from PEM file
+func LoadSigningKey(path string) (*ecdsa.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case ecPrivateKeyPEMType:
+		return x509.ParseECPrivateKey(block.Bytes)
+	case privateKeyPEMType:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an ECDSA key", path)
+		}
+		return ecKey, nil
+	case cosignEncryptedKeyPEM:
+		return nil, fmt.Errorf("encrypted cosign keys are not supported, generate signing key with 'overlock registry keygen'")
+	}
+	return nil, fmt.Errorf("unsupported signing key type %s", block.Type)
+}
+
+// Load ECDSA public key from PEM file
+func LoadVerificationKey(path string) (*ecdsa.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("verification key %s is not an ECDSA key", path)
+	}
+	return ecKey, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
+
+// Sign manifest pushed to local registry and push signature next to it
+func signLocal(ctx context.Context, imageName string, digest regv1.Hash, keyPath string, config *rest.Config, logger *zap.SugaredLogger) error {
+	key, err := LoadSigningKey(keyPath)
+	if err != nil {
+		return err
+	}
+	repo, err := localRepository(imageName)
+	if err != nil {
+		return err
+	}
+
+	payload := simpleSigning{}
+	payload.Critical.Identity.DockerReference = repo
+	payload.Critical.Image.DockerManifestDigest = digest.String()
+	payload.Critical.Type = signatureType
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(payloadBytes)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		return err
+	}
+
+	sigImage := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	sigImage = mutate.ConfigMediaType(sigImage, types.OCIConfigJSON)
+	sigImage, err = mutate.Append(sigImage, mutate.Addendum{
+		Layer: static.NewLayer(payloadBytes, signatureMediaType),
+		Annotations: map[string]string{
+			signatureAnnotation: b64.StdEncoding.EncodeToString(signature),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	sigName := imageRepository(imageName) + ":" + signatureTag(digest)
+	logger.Debugf("Pushing signature %s", sigName)
+	if err := PushLocalRegistry(ctx, sigName, sigImage, config, logger); err != nil {
+		return errors.Wrap(err, "failed to push signature")
+	}
+	logger.Infof("Image %s signed.", imageName)
+	return nil
+}
+
+// Verify signature of image in local registry with public key
+func VerifyLocalImage(ctx context.Context, reference string, keyPath string, config *rest.Config, logger *zap.SugaredLogger) error {
+	key, err := LoadVerificationKey(keyPath)
+	if err != nil {
+		return err
+	}
+	return withLocalRegistry(ctx, config, logger, func(host string, opts ...remote.Option) error {
+		ref, err := name.ParseReference(host + "/" + reference)
+		if err != nil {
+			return err
+		}
+		desc, err := remote.Head(ref, opts...)
+		if err != nil {
+			return errors.Wrapf(err, "image %s not found", reference)
+		}
+		sigImage, err := remote.Image(ref.Context().Tag(signatureTag(desc.Digest)), opts...)
+		if err != nil {
+			return fmt.Errorf("image %s is not signed", reference)
+		}
+		manifest, err := sigImage.Manifest()
+		if err != nil {
+			return err
+		}
+		for _, layerDesc := range manifest.Layers {
+			if layerDesc.MediaType != signatureMediaType {
+				continue
+			}
+			if verifySignatureLayer(sigImage, layerDesc, desc.Digest, key) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("no valid signature of %s found for provided key", reference)
+	})
+}
+
+func verifySignatureLayer(sigImage regv1.Image, layerDesc regv1.Descriptor, digest regv1.Hash, key *ecdsa.PublicKey) error {
+	signature, err := b64.StdEncoding.DecodeString(layerDesc.Annotations[signatureAnnotation])
+	if err != nil {
+		return err
+	}
+	layer, err := sigImage.LayerByDigest(layerDesc.Digest)
+	if err != nil {
+		return err
+	}
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	payloadBytes, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(payloadBytes)
+	if !ecdsa.VerifyASN1(key, hash[:], signature) {
+		return errors.New("signature mismatch")
+	}
+	payload := simpleSigning{}
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return err
+	}
+	if payload.Critical.Image.DockerManifestDigest != digest.String() {
+		return errors.New("signature is for another image")
+	}
+	return nil
+}
+
+// Tag of cosign signature for image digest
+func signatureTag(digest regv1.Hash) string {
+	return digest.Algorithm + "-" + digest.Hex + signatureTagSuffix
+}
+
+// Repository part of image name
+func imageRepository(imageName string) string {
+	repo, _ := packages.SplitReference(imageName)
+	return repo
+}
+
+// Repository of image as referenced in cluster
+func localRepository(imageName string) (string, error) {
+	local := NewLocal()
+	repo, err := name.NewRepository(local.LocalDomain() + "/" + imageRepository(imageName))
+	if err != nil {
+		return "", err
+	}
+	return repo.Name(), nil
+}
@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/kube"
+	"github.com/web-seven/overlock/internal/namespace"
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// Port forward from local address to local registry pod
+type Tunnel struct {
+	Pod     string
+	Address string
+	Port    int
+
+	stopChan  chan struct{}
+	done      chan struct{}
+	errOut    *bytes.Buffer
+	err       error
+	closeOnce sync.Once
+}
+
+var (
+	tunnelsMu sync.Mutex
+	tunnels   = map[string]*Tunnel{}
+)
+
+// Open port forward to local registry, listening on random port when port is 0
+func OpenTunnel(ctx context.Context, config *rest.Config, address string, port int, logger *zap.SugaredLogger) (*Tunnel, error) {
+	client, err := kube.Client(config)
+	if err != nil {
+		return nil, err
+	}
+	pod, err := localRegistryPod(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("Found local registry with name: %s", pod)
+
+	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return nil, err
+	}
+	serverURL, err := url.Parse(config.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse Kubernetes API server URL")
+	}
+	serverURL.Path = path.Join(serverURL.Path, "api/v1/namespaces", namespace.Namespace, "pods", pod, "portforward")
+	logger.Debugf("Dialer server URL: %s", serverURL.String())
+
+	t := &Tunnel{
+		Pod:      pod,
+		Address:  address,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+		errOut:   new(bytes.Buffer),
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, serverURL)
+	readyChan := make(chan struct{})
+	out := new(bytes.Buffer)
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{address}, []string{fmt.Sprintf("%d:%d", port, deployPort)}, t.stopChan, readyChan, out, t.errOut)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		t.err = forwarder.ForwardPorts()
+		close(t.done)
+	}()
+
+	select {
+	case <-readyChan:
+	case <-t.done:
+		return nil, errors.Wrap(t.Err(), "failed to forward local registry port")
+	case <-ctx.Done():
+		t.Close()
+		return nil, ctx.Err()
+	}
+	logger.Debug(strings.TrimSpace(out.String()))
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		t.Close()
+		return nil, errors.Wrap(err, "failed to get forwarded port")
+	}
+	t.Port = int(ports[0].Local)
+	return t, nil
+}
+
+// Address of local registry available through tunnel
+func (t *Tunnel) Host() string {
+	return net.JoinHostPort(t.Address, strconv.Itoa(t.Port))
+}
+
+// Closed when tunnel is closed or broken
+func (t *Tunnel) Done() <-chan struct{} {
+	return t.done
+}
+
+// Error which broke the tunnel
+func (t *Tunnel) Err() error {
+	if t.err != nil {
+		return t.err
+	}
+	if msg := strings.TrimSpace(t.errOut.String()); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+// Stop forwarding
+func (t *Tunnel) Close() {
+	t.closeOnce.Do(func() { close(t.stopChan) })
+}
+
+func (t *Tunnel) alive() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Tunnel shared by registry operations of the cluster, reopened when broken
+func sharedTunnel(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger) (*Tunnel, error) {
+	tunnelsMu.Lock()
+	defer tunnelsMu.Unlock()
+	if t, ok := tunnels[config.Host]; ok && t.alive() {
+		return t, nil
+	}
+	t, err := OpenTunnel(ctx, config, "localhost", 0, logger)
+	if err != nil {
+		return nil, err
+	}
+	tunnels[config.Host] = t
+	return t, nil
+}
+
+// Run fn with address of local registry available through shared tunnel
+func withLocalRegistry(ctx context.Context, config *rest.Config, logger *zap.SugaredLogger, fn func(host string, opts ...remote.Option) error) error {
+	t, err := sharedTunnel(ctx, config, logger)
+	if err != nil {
+		return err
+	}
+
+	// Use insecure transport for self-signed certificate
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	err = fn(t.Host(), remote.WithContext(ctx), remote.WithTransport(transport))
+	if err != nil && !t.alive() {
+		return errors.Wrapf(err, "local registry tunnel closed: %v", t.Err())
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/web-seven/overlock/internal/engine"
+	"github.com/web-seven/overlock/internal/kube"
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const RotatedAtAnnotation = "overlock-registry-rotated-at"
+
+var packageResources = []schema.GroupVersionResource{
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "providers"},
+	{Group: "pkg.crossplane.io", Version: "v1", Resource: "configurations"},
+	{Group: "pkg.crossplane.io", Version: "v1beta1", Resource: "functions"},
+}
+
+// Get registry by name from requested context
+func Get(ctx context.Context, client *kubernetes.Clientset, name string) (*Registry, error) {
+	registries, err := Registries(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, reg := range registries {
+		if reg.GetName() == name {
+			reg.Name = name
+			reg.Server = reg.Annotations[RegistryServerLabel]
+			reg.Local = name == LocalRegistryName
+			return reg, nil
+		}
+	}
+	return nil, fmt.Errorf("registry %s not found", name)
+}
+
+// Auth stored in registry secret
+func (r *Registry) Auth() (RegistryAuth, error) {
+	regConf := RegistryConfig{}
+	if err := json.Unmarshal(r.Data[".dockerconfigjson"], &regConf); err != nil {
+		return RegistryAuth{}, errors.Wrap(err, "failed to parse registry secret")
+	}
+	for _, auth := range regConf.Auths {
+		return auth, nil
+	}
+	return RegistryAuth{}, fmt.Errorf("registry %s has no credentials", r.Name)
+}
+
+// Replace credentials of registry in place and propagate them to the engine
+// release and packages which reference the registry secret.
+func (r *Registry) UpdateCredentials(ctx context.Context, config *rest.Config, creds Credentials, logger *zap.SugaredLogger) error {
+	if r.Local {
+		return fmt.Errorf("local registry has no credentials")
+	}
+
+	current, err := r.Auth()
+	if err != nil {
+		logger.Debug(err)
+	}
+	if creds.Server == "" {
+		creds.Server = current.Server
+	}
+	if creds.Username == "" {
+		creds.Username = current.Username
+	}
+	if creds.Email == "" {
+		creds.Email = current.Email
+	}
+	if creds.Password == "" {
+		return fmt.Errorf("password is required to update registry credentials")
+	}
+
+	oldDomain, _ := r.Domain()
+	updated := New(creds.Server, creds.Username, creds.Password, creds.Email)
+	updated.Name = r.Name
+	if err := updated.Validate(ctx, nil, logger); err != nil {
+		return err
+	}
+
+	client, err := kube.Client(config)
+	if err != nil {
+		return err
+	}
+	secret, err := secretClient(client).Get(ctx, r.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	secretSpec := updated.SecretSpec()
+	secret.Data = secretSpec.Data
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[RegistryServerLabel] = creds.Server
+	if _, err := secretClient(client).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	logger.Debug("Registry secret updated.")
+
+	r.Server = creds.Server
+	newDomain, _ := r.Domain()
+	if err := r.propagateEngine(ctx, config, oldDomain, newDomain); err != nil {
+		return errors.Wrap(err, "failed to update engine release")
+	}
+	logger.Debug("Engine release updated.")
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return r.propagatePackages(ctx, dynamicClient, logger)
+}
+
+// Ensure engine release references the registry and follows server changes
+func (r *Registry) propagateEngine(ctx context.Context, config *rest.Config, oldDomain string, newDomain string) error {
+	installer, err := engine.GetEngine(config)
+	if err != nil {
+		return err
+	}
+	release, err := installer.GetRelease()
+	if err != nil {
+		return err
+	}
+	if release.Config == nil {
+		release.Config = map[string]interface{}{}
+	}
+
+	changed := false
+	pullSecrets, _ := release.Config["imagePullSecrets"].([]interface{})
+	if !slices.Contains(pullSecrets, interface{}(r.Name)) {
+		release.Config["imagePullSecrets"] = append(pullSecrets, r.Name)
+		changed = true
+	}
+
+	if oldDomain != newDomain {
+		if args, ok := release.Config["args"].([]interface{}); ok {
+			for i, arg := range args {
+				if arg == "--registry="+oldDomain {
+					args[i] = "--registry=" + newDomain
+					changed = true
+				}
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	version, err := installer.GetCurrentVersion()
+	if err != nil {
+		return err
+	}
+	return installer.Upgrade(version, release.Config)
+}
+
+// Annotate packages pulled with registry secret, so engine retries with new credentials
+func (r *Registry) propagatePackages(ctx context.Context, dc dynamic.Interface, logger *zap.SugaredLogger) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, RotatedAtAnnotation, time.Now().UTC().Format(time.RFC3339))
+	for _, gvr := range packageResources {
+		list, err := dc.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, pkg := range list.Items {
+			secrets, _, _ := unstructured.NestedSlice(pkg.Object, "spec", "packagePullSecrets")
+			if !referencesSecret(secrets, r.Name) {
+				continue
+			}
+			logger.Debugf("Refreshing %s %s", pkg.GetKind(), pkg.GetName())
+			_, err := dc.Resource(gvr).Patch(ctx, pkg.GetName(), types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func referencesSecret(secrets []interface{}, name string) bool {
+	for _, secret := range secrets {
+		ref, ok := secret.(map[string]interface{})
+		if ok && ref["name"] == name {
+			return true
+		}
+	}
+	return false
+}